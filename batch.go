@@ -0,0 +1,88 @@
+package zillow
+
+import (
+	"context"
+	"sync"
+)
+
+func (z *zillow) GetZestimates(ctx context.Context, requests []ZestimateRequest, concurrency int) ([]*ZestimateResult, []error) {
+	results := make([]*ZestimateResult, len(requests))
+	errs := z.batch(ctx, len(requests), concurrency, func(ctx context.Context, i int) (err error) {
+		results[i], err = z.zestimate(ctx, requests[i])
+		return
+	})
+	return results, errs
+}
+
+func (z *zillow) GetDeepSearches(ctx context.Context, requests []SearchRequest, concurrency int) ([]*DeepSearchResults, []error) {
+	results := make([]*DeepSearchResults, len(requests))
+	errs := z.batch(ctx, len(requests), concurrency, func(ctx context.Context, i int) (err error) {
+		results[i], err = z.deepSearchResults(ctx, requests[i])
+		return
+	})
+	return results, errs
+}
+
+func (z *zillow) GetRegionCharts(ctx context.Context, requests []RegionChartRequest, concurrency int) ([]*RegionChartResult, []error) {
+	results := make([]*RegionChartResult, len(requests))
+	errs := z.batch(ctx, len(requests), concurrency, func(ctx context.Context, i int) (err error) {
+		results[i], err = z.regionChart(ctx, requests[i])
+		return
+	})
+	return results, errs
+}
+
+// batch calls call for each index in [0, n) with at most concurrency calls in
+// flight, and no more than the client's WithMaxConcurrency limit across all
+// batches. Calls share a ctx carrying the WithRetryBudget budget, if set. The
+// returned errors are indexed like the calls.
+func (z *zillow) batch(ctx context.Context, n, concurrency int, call func(ctx context.Context, i int) error) []error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	ctx = withRetryBudget(ctx, z.retryBudget)
+	errs := make([]error, n)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		if err := acquire(ctx, sem); err != nil {
+			errs[i] = err
+			continue
+		}
+		if err := acquire(ctx, z.sem); err != nil {
+			<-sem
+			errs[i] = err
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				release(z.sem)
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = call(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+// acquire takes a slot from sem, which may be nil for no limit.
+func acquire(ctx context.Context, sem chan struct{}) error {
+	if sem == nil {
+		return nil
+	}
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func release(sem chan struct{}) {
+	if sem != nil {
+		<-sem
+	}
+}
@@ -0,0 +1,158 @@
+package zillow
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithMaxConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		b, err := ioutil.ReadFile("testdata/" + strings.TrimSuffix(path.Base(r.URL.Path), ".htm") + ".xml")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		w.Write(b)
+	}))
+	defer server.Close()
+
+	z := NewExt(testZwsId, server.URL, WithMaxConcurrency(2))
+
+	zestimates := make([]ZestimateRequest, 6)
+	for i := range zestimates {
+		zestimates[i] = ZestimateRequest{Zpid: zpid}
+	}
+	charts := make([]RegionChartRequest, 6)
+	for i := range charts {
+		charts[i] = RegionChartRequest{City: city, State: state, UnitType: unitType, Width: width, Height: height}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, errs := z.GetZestimates(context.Background(), zestimates, 3)
+		for _, err := range errs {
+			if err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		_, errs := z.GetRegionCharts(context.Background(), charts, 3)
+		for _, err := range errs {
+			if err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	wg.Wait()
+
+	if maxInFlight > 2 {
+		t.Fatalf("expected at most 2 requests in flight but got %d", maxInFlight)
+	}
+}
+
+func TestWithMaxConcurrencyUnlimited(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		server, z := testFixture(t, zestimatePath, zestimatePath, func(url.Values) {}, WithMaxConcurrency(n))
+		requests := []ZestimateRequest{{Zpid: zpid}, {Zpid: zpid}}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, errs := z.GetZestimates(ctx, requests, 2)
+		cancel()
+		server.Close()
+		for i, err := range errs {
+			if err != nil {
+				t.Errorf("%d: request %d: expected no limit but got %v", n, i, err)
+			}
+		}
+	}
+}
+
+func TestGetDeepSearches(t *testing.T) {
+	server, zillow := testFixtures(t, deepSearchPath, func(url.Values) {})
+	defer server.Close()
+
+	requests := []SearchRequest{
+		{Address: address, CityStateZip: citystatezip},
+		{Address: address, CityStateZip: citystatezip},
+	}
+	results, errs := zillow.GetDeepSearches(context.Background(), requests, 2)
+	for i := range requests {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if results[i].Results[0].Zpid != zpid {
+			t.Errorf("%d: expected zpid %s but got %#v", i, zpid, results[i])
+		}
+	}
+}
+
+func TestWithRetryBudget(t *testing.T) {
+	// Every response is cut off, so every call is retried until it runs out.
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 1000\r\n\r\n<zestimate:zestimate>")
+		buf.Flush()
+	}))
+	defer server.Close()
+
+	const budget = 200 * time.Millisecond
+	for _, backoff := range []time.Duration{50 * time.Millisecond, 0} {
+		atomic.StoreInt32(&calls, 0)
+		backoff := backoff
+		z := NewExt(testZwsId, server.URL,
+			WithRetry(1<<20, RetryBackoff(func(int) time.Duration { return backoff })),
+			WithRetryBudget(budget))
+
+		requests := make([]ZestimateRequest, 4)
+		for i := range requests {
+			requests[i] = ZestimateRequest{Zpid: zpid}
+		}
+		start := time.Now()
+		_, errs := z.GetZestimates(context.Background(), requests, len(requests))
+		// Unbounded, each call would retry 1<<20 times.
+		if elapsed := time.Since(start); elapsed > 2*budget {
+			t.Errorf("%s: expected batch to return within the budget of %s but took %s", backoff, budget, elapsed)
+		}
+		for i, err := range errs {
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Errorf("%s: %d: expected the original DecodeError but got %v", backoff, i, err)
+			}
+		}
+		if n := atomic.LoadInt32(&calls); n <= int32(len(requests)) {
+			t.Errorf("%s: expected retries within the budget but got %d calls", backoff, n)
+		} else if backoff > 0 && n > int32(len(requests))*int32(1+budget/backoff) {
+			t.Errorf("%s: expected at most %d retries per call but got %d calls", backoff, budget/backoff, n)
+		}
+	}
+}
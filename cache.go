@@ -0,0 +1,41 @@
+package zillow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// cacheKey returns a key identifying the call of path with req, a request
+// struct. Logically identical requests get the same key: zero-valued fields
+// are omitted, so rentzestimate=false is the same as not set, addresses are
+// normalized, and the zws-id is never included.
+func cacheKey(path string, req interface{}) string {
+	values := url.Values{}
+	v := reflect.Indirect(reflect.ValueOf(req))
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := v.Field(i)
+		name := paramName(t.Field(i))
+		if name == "" || name == zwsIdParam || f.IsZero() {
+			continue
+		}
+		s := formatParam(f)
+		if name == addressParam || name == cityStateZipParam {
+			s = normalizeAddress(s)
+		}
+		values.Set(name, s)
+	}
+	// Encode sorts by key.
+	sum := sha256.Sum256([]byte(path + "?" + values.Encode()))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeAddress lower cases s, drops periods and collapses whitespace, so
+// that "2114 Bigelow Ave." and "2114  bigelow ave" are equivalent.
+func normalizeAddress(s string) string {
+	s = strings.ToLower(strings.Replace(s, ".", "", -1))
+	return strings.Join(strings.Fields(s), " ")
+}
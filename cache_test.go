@@ -0,0 +1,33 @@
+package zillow
+
+import "testing"
+
+func TestCacheKey(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		a, b interface{}
+	}{
+		{"default rentzestimate", ZestimateRequest{Zpid: zpid}, ZestimateRequest{Zpid: zpid, Rentzestimate: false}},
+		{"pointer", ZestimateRequest{Zpid: zpid}, &ZestimateRequest{Zpid: zpid}},
+		{"address", SearchRequest{Address: address, CityStateZip: citystatezip}, SearchRequest{Address: " 2114  bigelow AVE. ", CityStateZip: "seattle,  wa"}},
+		{"zero count", CompsRequest{Zpid: zpid}, CompsRequest{Zpid: zpid, Count: 0}},
+	} {
+		if a, b := cacheKey(zestimatePath, c.a), cacheKey(zestimatePath, c.b); a != b {
+			t.Errorf("%s: expected equal keys but got %s and %s", c.name, a, b)
+		}
+	}
+
+	for _, c := range []struct {
+		name         string
+		pathA, pathB string
+		a, b         interface{}
+	}{
+		{"path", zestimatePath, chartPath, ZestimateRequest{Zpid: zpid}, ZestimateRequest{Zpid: zpid}},
+		{"zpid", zestimatePath, zestimatePath, ZestimateRequest{Zpid: zpid}, ZestimateRequest{Zpid: "1"}},
+		{"rentzestimate", zestimatePath, zestimatePath, ZestimateRequest{Zpid: zpid}, ZestimateRequest{Zpid: zpid, Rentzestimate: true}},
+	} {
+		if a, b := cacheKey(c.pathA, c.a), cacheKey(c.pathB, c.b); a == b {
+			t.Errorf("%s: expected different keys but both were %s", c.name, a)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package zillow
+
+import (
+	"fmt"
+	"math"
+)
+
+// RoundingMode is how the calculator helpers, such as
+// MonthlyPrincipalAndInterest, round to whole dollars.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest dollar, and halves away from zero. It
+	// is the default, as the more common convention. Zillow's sample outputs
+	// match it, but have no exact halves to tell it from RoundBankers.
+	RoundHalfUp RoundingMode = iota
+	// RoundBankers rounds to the nearest dollar, and halves to even.
+	RoundBankers
+	// Truncate drops the cents.
+	Truncate
+)
+
+func (m RoundingMode) round(x float64) int {
+	switch m {
+	case RoundBankers:
+		return int(math.RoundToEven(x))
+	case Truncate:
+		return int(math.Trunc(x))
+	}
+	return int(math.Round(x))
+}
+
+type calculator struct {
+	rounding RoundingMode
+}
+
+// CalculatorOption configures a calculator helper.
+type CalculatorOption func(*calculator)
+
+// Rounding sets how results are rounded to whole dollars. The default is
+// RoundHalfUp.
+func Rounding(mode RoundingMode) CalculatorOption {
+	return func(c *calculator) {
+		c.rounding = mode
+	}
+}
+
+func newCalculator(opts []CalculatorOption) calculator {
+	var c calculator
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// exactMonthlyPayment returns the exact monthly payment of a fixed rate loan.
+func exactMonthlyPayment(loan, rate float64, termInMonths int) float64 {
+	r := rate / 100 / 12
+	if r == 0 {
+		return loan / float64(termInMonths)
+	}
+	return loan * r / (1 - math.Pow(1+r, -float64(termInMonths)))
+}
+
+// MonthlyPrincipalAndInterest returns the monthly payment of a fixed rate loan
+// at rate, an annual percentage such as 6 for 6%, over termInMonths, like
+// MonthlyPaymentsAdvanced.MonthlyPrincipalAndInterest. It returns 0 for a term
+// which isn't positive. It makes no API call.
+func MonthlyPrincipalAndInterest(loan int, rate float64, termInMonths int, opts ...CalculatorOption) int {
+	if termInMonths <= 0 {
+		return 0
+	}
+	c := newCalculator(opts)
+	return c.rounding.round(exactMonthlyPayment(float64(loan), rate, termInMonths))
+}
+
+// Amortize returns the amortization schedule of a loan like
+// MonthlyPrincipalAndInterest. schedule is "monthly" or "yearly", as for
+// MonthlyPaymentsAdvancedRequest; a yearly period sums its months, and any
+// other schedule is an error. Like Zillow, balances are carried between periods
+// unrounded, so only the reported amounts are rounded. It makes no API call.
+func Amortize(loan int, rate float64, termInMonths int, schedule string, opts ...CalculatorOption) (AmortizationSchedule, error) {
+	c := newCalculator(opts)
+	var s AmortizationSchedule
+	var monthsPerPeriod int
+	switch schedule {
+	case "monthly":
+		s.Frequency, monthsPerPeriod = "monthly", 1
+	case "yearly":
+		s.Frequency, monthsPerPeriod = "annual", 12
+	default:
+		return s, fmt.Errorf("zillow: unknown schedule %q, expected monthly or yearly", schedule)
+	}
+	if termInMonths <= 0 {
+		return s, nil
+	}
+	payment := exactMonthlyPayment(float64(loan), rate, termInMonths)
+	r := rate / 100 / 12
+	balance := float64(loan)
+	for month := 0; month < termInMonths; {
+		beginning := balance
+		var amount, principal, interest float64
+		for i := 0; i < monthsPerPeriod && month < termInMonths; i, month = i+1, month+1 {
+			in := balance * r
+			amount += payment
+			interest += in
+			principal += payment - in
+			balance -= payment - in
+		}
+		s.Payments = append(s.Payments, AdvancedPayment{
+			BeginningBalance: c.rounding.round(beginning),
+			Amount:           c.rounding.round(amount),
+			Principal:        c.rounding.round(principal),
+			Interest:         c.rounding.round(interest),
+			EndingBalance:    c.rounding.round(balance),
+		})
+	}
+	return s, nil
+}
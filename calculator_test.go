@@ -0,0 +1,94 @@
+package zillow
+
+import "testing"
+
+func TestRoundingMode(t *testing.T) {
+	for _, c := range []struct {
+		x                      float64
+		halfUp, bankers, trunc int
+	}{
+		{1438.92, 1439, 1439, 1438},
+		{2.5, 3, 2, 2},
+		{3.5, 4, 4, 3},
+		{-2.5, -3, -2, -2},
+		{2.49, 2, 2, 2},
+	} {
+		if actual := RoundHalfUp.round(c.x); actual != c.halfUp {
+			t.Errorf("RoundHalfUp %v: expected %d but got %d", c.x, c.halfUp, actual)
+		}
+		if actual := RoundBankers.round(c.x); actual != c.bankers {
+			t.Errorf("RoundBankers %v: expected %d but got %d", c.x, c.bankers, actual)
+		}
+		if actual := Truncate.round(c.x); actual != c.trunc {
+			t.Errorf("Truncate %v: expected %d but got %d", c.x, c.trunc, actual)
+		}
+	}
+}
+
+func TestCalculatorMatchesMonthlyPaymentsAdvanced(t *testing.T) {
+	var result MonthlyPaymentsAdvanced
+	decodeFixture(t, monthlyPaymentsAdvancedPath, &result)
+	loan := result.AmortizationSchedule.Payments[0].BeginningBalance
+	rate := float64(result.Request.Rate)
+	term := result.Request.TermInMonths
+
+	// The fixture has no exact halves, so RoundHalfUp and RoundBankers both
+	// match it; RoundHalfUp is the default, as the more common convention.
+	for _, c := range []struct {
+		name    string
+		opts    []CalculatorOption
+		matches bool
+	}{
+		{"default", nil, true},
+		{"RoundHalfUp", []CalculatorOption{Rounding(RoundHalfUp)}, true},
+		{"RoundBankers", []CalculatorOption{Rounding(RoundBankers)}, true},
+		{"Truncate", []CalculatorOption{Rounding(Truncate)}, false},
+	} {
+		payment := MonthlyPrincipalAndInterest(loan, rate, term, c.opts...)
+		schedule, err := Amortize(loan, rate, term, result.Request.Schedule, c.opts...)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if len(schedule.Payments) != term/12 || schedule.Frequency != result.AmortizationSchedule.Frequency {
+			t.Fatalf("%s: expected %d %s payments but got %d %s", c.name, term/12, result.AmortizationSchedule.Frequency, len(schedule.Payments), schedule.Frequency)
+		}
+		matches := payment == result.MonthlyPrincipalAndInterest
+		for i, expected := range result.AmortizationSchedule.Payments {
+			matches = matches && schedule.Payments[i] == expected
+		}
+		if matches != c.matches {
+			t.Errorf("%s: expected match %t but got payment %d and schedule %+v", c.name, c.matches, payment, schedule.Payments[:3])
+		}
+	}
+}
+
+func TestAmortizeMonthly(t *testing.T) {
+	schedule, err := Amortize(240000, 6, 360, "monthly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedule.Payments) != 360 || schedule.Frequency != "monthly" {
+		t.Fatalf("expected 360 monthly payments but got %d %s", len(schedule.Payments), schedule.Frequency)
+	}
+	first, last := schedule.Payments[0], schedule.Payments[359]
+	if expected := (AdvancedPayment{BeginningBalance: 240000, Amount: 1439, Principal: 239, Interest: 1200, EndingBalance: 239761}); first != expected {
+		t.Errorf("expected first payment %+v but got %+v", expected, first)
+	}
+	if last.EndingBalance != 0 {
+		t.Errorf("expected the loan to be paid off but got %+v", last)
+	}
+
+	// Without interest, the loan is repaid evenly.
+	if payment := MonthlyPrincipalAndInterest(120000, 0, 360); payment != 333 {
+		t.Errorf("expected 333 but got %d", payment)
+	}
+	if payment := MonthlyPrincipalAndInterest(120000, 6, 0); payment != 0 {
+		t.Errorf("expected 0 for no term but got %d", payment)
+	}
+
+	for _, schedule := range []string{"", "annual", "weekly", "Monthly"} {
+		if _, err := Amortize(240000, 6, 360, schedule); err == nil {
+			t.Errorf("%q: expected error for unknown schedule", schedule)
+		}
+	}
+}
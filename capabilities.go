@@ -0,0 +1,90 @@
+package zillow
+
+import "context"
+
+// capabilityGroups pairs a cheap probe with the methods sharing its entitlement.
+// Probes call get directly, so the client's checks of decoded results, like
+// WithStrictSingleResult, can't fail them; only the message code matters.
+var capabilityGroups = []struct {
+	methods []string
+	probe   func(context.Context, *zillow) (Message, error)
+}{
+	{
+		methods: []string{"GetZestimate", "GetSearchResults", "GetChart", "GetComps", "GetRentZestimate"},
+		probe: func(ctx context.Context, z *zillow) (Message, error) {
+			var r ZestimateResult
+			err := z.get(ctx, zestimatePath, ZestimateRequest{Zpid: "48749425"}, &r)
+			return r.Message, err
+		},
+	},
+	{
+		methods: []string{"GetDeepComps", "GetDeepSearchResults", "GetUpdatedPropertyDetails"},
+		probe: func(ctx context.Context, z *zillow) (Message, error) {
+			var r DeepSearchResults
+			err := z.get(ctx, deepSearchPath, SearchRequest{Address: "2114 Bigelow Ave", CityStateZip: "Seattle, WA"}, &r)
+			return r.Message, err
+		},
+	},
+	{
+		methods: []string{"GetRegionChildren", "GetRegionChart"},
+		probe: func(ctx context.Context, z *zillow) (Message, error) {
+			var r RegionChildren
+			err := z.get(ctx, regionChildrenPath, RegionChildrenRequest{State: "wa"}, &r)
+			return r.Message, err
+		},
+	},
+	{
+		methods: []string{"GetRateSummary"},
+		probe: func(ctx context.Context, z *zillow) (Message, error) {
+			var r RateSummary
+			err := z.get(ctx, rateSummaryPath, RateSummaryRequest{}, &r)
+			return r.Message, err
+		},
+	},
+	{
+		methods: []string{"GetMonthlyPayments", "CalculateMonthlyPaymentsAdvanced", "CalculateAffordability"},
+		probe: func(ctx context.Context, z *zillow) (Message, error) {
+			var r MonthlyPayments
+			err := z.get(ctx, monthlyPaymentsPath, MonthlyPaymentsRequest{Price: 300000}, &r)
+			return r.Message, err
+		},
+	},
+}
+
+func (z *zillow) Capabilities(ctx context.Context) (map[string]bool, error) {
+	z.capabilitiesMu.Lock()
+	defer z.capabilitiesMu.Unlock()
+
+	if z.capabilities == nil {
+		capabilities := make(map[string]bool)
+		for _, g := range capabilityGroups {
+			msg, err := g.probe(ctx, z)
+			if err != nil {
+				return nil, err
+			}
+			// Any other code, like a bad probe input, still means the call went through.
+			usable := msg.Code != codeInvalidZWSID && msg.Code != codeNotEntitled
+			for _, m := range g.methods {
+				capabilities[m] = usable
+			}
+		}
+		z.capabilities = capabilities
+	}
+
+	c := make(map[string]bool, len(z.capabilities))
+	for m, ok := range z.capabilities {
+		c[m] = ok
+	}
+	return c, nil
+}
+
+// pingState is the state whose rate summary Ping requests.
+const pingState = "WA"
+
+func (z *zillow) Ping(ctx context.Context) error {
+	r, err := z.rateSummary(ctx, RateSummaryRequest{State: pingState})
+	if err != nil {
+		return err
+	}
+	return r.Message.Err()
+}
@@ -0,0 +1,135 @@
+package zillow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// errorBody returns an error-only response with the given root element.
+func errorBody(root string, code int) string {
+	return fmt.Sprintf("<%s><request/><message><text>error</text><code>%d</code></message></%s>", root, code, root)
+}
+
+func TestCapabilities(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch endpoint := strings.TrimSuffix(path.Base(r.URL.Path), ".htm"); endpoint {
+		case deepSearchPath:
+			io.WriteString(w, errorBody("searchresults", codeNotEntitled))
+		case regionChildrenPath:
+			io.WriteString(w, errorBody("regionchildren", 502))
+		default:
+			f, err := os.Open("testdata/" + endpoint + ".xml")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer f.Close()
+			io.Copy(w, f)
+		}
+	}))
+	defer server.Close()
+
+	z := &zillow{zwsId: testZwsId, url: server.URL}
+	capabilities, err := z.Capabilities(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]bool{
+		"GetZestimate":                     true,
+		"GetSearchResults":                 true,
+		"GetChart":                         true,
+		"GetComps":                         true,
+		"GetRentZestimate":                 true,
+		"GetDeepComps":                     false,
+		"GetDeepSearchResults":             false,
+		"GetUpdatedPropertyDetails":        false,
+		"GetRegionChildren":                true,
+		"GetRegionChart":                   true,
+		"GetRateSummary":                   true,
+		"GetMonthlyPayments":               true,
+		"CalculateMonthlyPaymentsAdvanced": true,
+		"CalculateAffordability":           true,
+	}
+	if !reflect.DeepEqual(capabilities, expected) {
+		t.Fatalf("expected %v but got %v", expected, capabilities)
+	}
+
+	probes := calls
+	if _, err := z.Capabilities(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != probes {
+		t.Fatalf("expected cached capabilities but made %d more calls", calls-probes)
+	}
+}
+
+func TestCapabilitiesIgnoresChecks(t *testing.T) {
+	// The deep search probe matches several properties, which strict single
+	// result mode would reject.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint := strings.TrimSuffix(path.Base(r.URL.Path), ".htm")
+		if endpoint == deepSearchPath {
+			endpoint = "GetDeepSearchResultsMulti"
+		}
+		f, err := os.Open("testdata/" + endpoint + ".xml")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer f.Close()
+		io.Copy(w, f)
+	}))
+	defer server.Close()
+
+	z := &zillow{zwsId: testZwsId, url: server.URL}
+	for _, opt := range []Option{WithStrictSingleResult(), WithRequestEchoCheck(), WithMinComps(10)} {
+		opt(z)
+	}
+	capabilities, err := z.Capabilities(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for m, ok := range capabilities {
+		if !ok {
+			t.Errorf("expected %s to be usable", m)
+		}
+	}
+}
+
+func TestPing(t *testing.T) {
+	server, client := testFixture(t, rateSummaryPath, rateSummaryPath, func(values url.Values) {
+		assertOnlyParam(t, values, stateParam, pingState)
+	})
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("expected successful ping but got %v", err)
+	}
+	server.Close()
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, errorBody("rateSummary", codeInvalidZWSID))
+	}))
+	invalid := &zillow{zwsId: "bad", url: server.URL}
+	if err := invalid.Ping(context.Background()); !errors.Is(err, ErrInvalidZWSID) {
+		t.Errorf("expected ErrInvalidZWSID but got %v", err)
+	}
+	server.Close()
+
+	// The server is down now.
+	down := &zillow{zwsId: testZwsId, url: server.URL}
+	var urlErr *url.Error
+	if err := down.Ping(context.Background()); !errors.As(err, &urlErr) {
+		t.Errorf("expected network error but got %v", err)
+	}
+}
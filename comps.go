@@ -0,0 +1,179 @@
+package zillow
+
+import (
+	"encoding/xml"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Comps are the comparables of a CompsResult.
+type Comps []Comp
+
+// UnmarshalXML decodes the comp children of a <comparables> element. The
+// result is never nil, even if there are none.
+func (c *Comps) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v struct {
+		Comps []Comp `xml:"comp"`
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*c = append(Comps{}, v.Comps...)
+	return nil
+}
+
+// DeepComps are the comparables of a DeepCompsResult.
+type DeepComps []DeepComp
+
+// UnmarshalXML is like Comps.UnmarshalXML.
+func (c *DeepComps) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v struct {
+		Comps []DeepComp `xml:"comp"`
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*c = append(DeepComps{}, v.Comps...)
+	return nil
+}
+
+// checkMinComps returns an *InsufficientCompsError if a minimum is set and a
+// successful response has fewer comparables. Failures reported by
+// the message are left to the caller.
+func (z *zillow) checkMinComps(m Message, found int) error {
+	if z.minComps == 0 || m.Code != codeOK || found >= z.minComps {
+		return nil
+	}
+	return &InsufficientCompsError{Found: found, Min: z.minComps}
+}
+
+// maxCompsCount is the most comparables Zillow returns per call.
+const maxCompsCount = 25
+
+// codeInvalidCount is the comps endpoints' code for an invalid or missing
+// parameter. It isn't specific to count, so checkCount also looks for count in
+// the message text.
+const codeInvalidCount = 501
+
+// compsRequest returns request with a Count over maxCompsCount lowered to it,
+// or a *CountTooHighError with WithStrictCompsCount.
+func (z *zillow) compsRequest(request CompsRequest) (CompsRequest, error) {
+	if request.Count > maxCompsCount {
+		if z.strictCompsCount {
+			return request, &CountTooHighError{Count: request.Count, Max: maxCompsCount}
+		}
+		request.Count = maxCompsCount
+	}
+	return request, nil
+}
+
+// checkCount returns a *CountTooHighError if Zillow rejected a positive count.
+func checkCount(m Message, count int) error {
+	if m.Code != codeInvalidCount || count <= 0 || !strings.Contains(strings.ToLower(m.Text), "count") {
+		return nil
+	}
+	return &CountTooHighError{Count: count, Text: m.Text}
+}
+
+// Truncated reports whether Zillow may have returned fewer comparables than
+// exist because it capped the request, rather than because there were no more.
+// That is the case when the count echoed in Request, or the limit of 25, is
+// lower than requested and the comparables fill it. Fewer comparables than the
+// effective count means all were returned.
+func (r *CompsResult) Truncated(requested int) bool {
+	return truncated(requested, r.Request.Count, len(r.Comparables))
+}
+
+// Truncated is like CompsResult.Truncated.
+func (r *DeepCompsResult) Truncated(requested int) bool {
+	return truncated(requested, r.Request.Count, len(r.Comparables))
+}
+
+func truncated(requested, echoed, returned int) bool {
+	limit := requested
+	if echoed > 0 && echoed < limit {
+		limit = echoed
+	}
+	if limit > maxCompsCount {
+		limit = maxCompsCount
+	}
+	return limit < requested && returned >= limit
+}
+
+// CompStats summarizes the comparables of a DeepCompsResult. Each statistic
+// is computed over only the comps that report the values it needs, and is
+// zero if none do.
+type CompStats struct {
+	// ZestimateCount is the number of comps with a Zestimate amount.
+	ZestimateCount  int
+	MedianZestimate float64
+	MeanZestimate   float64
+	// PricePerSqFtCount is the number of comps with both a Zestimate amount
+	// and a non-zero FinishedSqFt.
+	PricePerSqFtCount  int
+	MedianPricePerSqFt float64
+	// LastSoldPriceCount is the number of comps with a LastSoldPrice.
+	LastSoldPriceCount  int
+	MedianLastSoldPrice float64
+}
+
+// Stats computes summary statistics over the comparables.
+func (r *DeepCompsResult) Stats() CompStats {
+	var zestimates, ppsf, sold []float64
+	var sum float64
+	for _, c := range r.Comparables {
+		if c.Zestimate.Amount.IsPresent() {
+			amount := float64(c.Zestimate.Amount.Value)
+			zestimates = append(zestimates, amount)
+			sum += amount
+			if c.FinishedSqFt != 0 {
+				ppsf = append(ppsf, amount/float64(c.FinishedSqFt))
+			}
+		}
+		if c.LastSoldPrice.IsPresent() {
+			sold = append(sold, float64(c.LastSoldPrice.Value))
+		}
+	}
+	var stats CompStats
+	if stats.ZestimateCount = len(zestimates); stats.ZestimateCount > 0 {
+		stats.MedianZestimate = median(zestimates)
+		stats.MeanZestimate = sum / float64(stats.ZestimateCount)
+	}
+	if stats.PricePerSqFtCount = len(ppsf); stats.PricePerSqFtCount > 0 {
+		stats.MedianPricePerSqFt = median(ppsf)
+	}
+	if stats.LastSoldPriceCount = len(sold); stats.LastSoldPriceCount > 0 {
+		stats.MedianLastSoldPrice = median(sold)
+	}
+	return stats
+}
+
+// OutlierComps returns the comparables whose Zestimate per square foot differs
+// from the principal's by more than threshold, as a fraction of the
+// principal's, e.g. 0.25 for 25%. Comps with zero FinishedSqFt are skipped,
+// and there are no outliers if the principal has none.
+func (r *DeepCompsResult) OutlierComps(threshold float64) []DeepComp {
+	principal, ok := perSqFt(r.Principal.Zestimate.Amount.Value, r.Principal.FinishedSqFt)
+	if !ok || principal == 0 {
+		return nil
+	}
+	var outliers []DeepComp
+	for _, c := range r.Comparables {
+		ppsf, ok := perSqFt(c.Zestimate.Amount.Value, c.FinishedSqFt)
+		if ok && math.Abs(ppsf-principal)/principal > threshold {
+			outliers = append(outliers, c)
+		}
+	}
+	return outliers
+}
+
+// median returns the median of vs, sorting it in place. vs must not be empty.
+func median(vs []float64) float64 {
+	sort.Float64s(vs)
+	n := len(vs)
+	if n%2 == 1 {
+		return vs[n/2]
+	}
+	return (vs[n/2-1] + vs[n/2]) / 2
+}
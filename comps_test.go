@@ -0,0 +1,274 @@
+package zillow
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+func TestDeepCompsResultStats(t *testing.T) {
+	var result DeepCompsResult
+	decodeFixture(t, "GetDeepCompsStats", &result)
+
+	// The third comp has no lastSoldPrice.
+	stats := result.Stats()
+	if stats.ZestimateCount != 3 || stats.PricePerSqFtCount != 3 || stats.LastSoldPriceCount != 2 {
+		t.Fatalf("expected counts 3, 3 and 2 but got %+v", stats)
+	}
+	for _, c := range []struct {
+		name             string
+		expected, actual float64
+	}{
+		{"median zestimate", 700000, stats.MedianZestimate},
+		{"mean zestimate", (836500.0 + 608000 + 700000) / 3, stats.MeanZestimate},
+		{"median price per sqft", 836500.0 / 2520, stats.MedianPricePerSqFt},
+		{"median last sold price", 713750, stats.MedianLastSoldPrice},
+	} {
+		if math.Abs(c.expected-c.actual) > 1e-9 {
+			t.Errorf("expected %s %f but got %f", c.name, c.expected, c.actual)
+		}
+	}
+
+	result.Comparables[0].FinishedSqFt = 0
+	if stats := result.Stats(); stats.PricePerSqFtCount != 2 || stats.ZestimateCount != 3 {
+		t.Fatalf("expected zero sqft comp to be skipped for price per sqft only but got %+v", stats)
+	}
+
+	result.Comparables[1].Zestimate.Amount = Value{}
+	stats = result.Stats()
+	if stats.ZestimateCount != 2 || stats.MedianZestimate != (836500.0+700000)/2 || stats.LastSoldPriceCount != 2 {
+		t.Fatalf("expected comp without zestimate to be skipped but got %+v", stats)
+	}
+
+	if stats := (&DeepCompsResult{}).Stats(); stats != (CompStats{}) {
+		t.Errorf("expected zero stats but got %+v", stats)
+	}
+}
+
+func TestCompsComparablesShape(t *testing.T) {
+	var found, empty, absent CompsResult
+	decodeFixture(t, compsPath, &found)
+	decodeFixture(t, "GetCompsEmpty", &empty)
+	decodeFixture(t, "GetCompsNoComparables", &absent)
+
+	if len(found.Comparables) != 2 {
+		t.Errorf("expected 2 comparables but got %d", len(found.Comparables))
+	}
+	if empty.Comparables == nil || len(empty.Comparables) != 0 {
+		t.Errorf("expected empty non-nil comparables but got %#v", empty.Comparables)
+	}
+	if absent.Comparables != nil {
+		t.Errorf("expected nil comparables but got %#v", absent.Comparables)
+	}
+	if absent.Principal.Zpid != zpid {
+		t.Errorf("expected principal %s but got %#v", zpid, absent.Principal)
+	}
+}
+
+func TestDeepCompsResultOutlierComps(t *testing.T) {
+	var result DeepCompsResult
+	decodeFixture(t, "GetDeepCompsOutliers", &result)
+
+	// The principal is ~$351/sqft; the comps are $360, $340, $750 and no sqft.
+	outliers := result.OutlierComps(0.25)
+	if len(outliers) != 1 || outliers[0].Zpid != "48749430" {
+		t.Fatalf("expected only comp 48749430 but got %#v", outliers)
+	}
+	if outliers := result.OutlierComps(0.01); len(outliers) != 3 {
+		t.Fatalf("expected 3 outliers at 1%% but got %d", len(outliers))
+	}
+	if outliers := result.OutlierComps(2); len(outliers) != 0 {
+		t.Fatalf("expected no outliers at 200%% but got %d", len(outliers))
+	}
+
+	result.Principal.FinishedSqFt = 0
+	if outliers := result.OutlierComps(0.25); outliers != nil {
+		t.Fatalf("expected no outliers without principal sqft but got %#v", outliers)
+	}
+}
+
+func TestCompsTruncated(t *testing.T) {
+	var result CompsResult
+	decodeFixture(t, compsPath, &result)
+	var deep DeepCompsResult
+	decodeFixture(t, deepCompsPath, &deep)
+
+	// 2 comps for a requested and echoed count of 5 are all there are.
+	if result.Truncated(5) || deep.Truncated(5) {
+		t.Error("expected fixtures not to be truncated")
+	}
+
+	// Zillow echoing a lower count than requested, and filling it.
+	result.Request.Count = 2
+	if !result.Truncated(5) {
+		t.Error("expected truncation when the echoed count is capped and filled")
+	}
+
+	// More than the limit requested, and the limit returned.
+	deep.Request.Count = 30
+	deep.Comparables = make(DeepComps, maxCompsCount)
+	if !deep.Truncated(30) {
+		t.Error("expected truncation at the limit")
+	}
+	if deep.Truncated(maxCompsCount) {
+		t.Error("expected no truncation when the limit was requested")
+	}
+}
+
+func TestDeepPrincipalRegions(t *testing.T) {
+	var result DeepCompsResult
+	decodeFixture(t, "GetDeepCompsRegions", &result)
+
+	expected := Regions{ZipcodeID: "99569", CityID: "16037", CountyID: "207", StateID: "59"}
+	if result.Principal.Regions != expected {
+		t.Fatalf("expected %#v but got %#v", expected, result.Principal.Regions)
+	}
+
+	var without DeepCompsResult
+	decodeFixture(t, deepCompsPath, &without)
+	if without.Principal.Regions != (Regions{}) {
+		t.Fatalf("expected no regions but got %#v", without.Principal.Regions)
+	}
+}
+
+func TestWithMinComps(t *testing.T) {
+	for _, c := range []struct {
+		path, fixture string
+		min           int
+		found         int
+	}{
+		{deepCompsPath, deepCompsPath, 2, -1},
+		{deepCompsPath, "GetDeepCompsOutliers", 3, -1},
+		{deepCompsPath, deepCompsPath, 3, 2},
+		{deepCompsPath, "GetDeepCompsNotEntitled", 3, -1},
+		{compsPath, compsPath, 2, -1},
+		{compsPath, "GetCompsEmpty", 1, 0},
+	} {
+		server, zillow := testFixture(t, c.path, c.fixture, func(url.Values) {}, WithMinComps(c.min))
+		var err error
+		if c.path == compsPath {
+			_, err = zillow.GetComps(CompsRequest{Zpid: zpid, Count: count})
+		} else {
+			_, err = zillow.GetDeepComps(CompsRequest{Zpid: zpid, Count: count})
+		}
+		server.Close()
+
+		if c.found < 0 {
+			if err != nil {
+				t.Errorf("%s with min %d: unexpected error %v", c.fixture, c.min, err)
+			}
+			continue
+		}
+		var insufficient *InsufficientCompsError
+		if !errors.Is(err, ErrInsufficientComps) || !errors.As(err, &insufficient) {
+			t.Errorf("%s with min %d: expected ErrInsufficientComps but got %v", c.fixture, c.min, err)
+		} else if *insufficient != (InsufficientCompsError{Found: c.found, Min: c.min}) {
+			t.Errorf("%s with min %d: expected %d found but got %+v", c.fixture, c.min, c.found, *insufficient)
+		}
+	}
+}
+
+func TestCompRentZestimate(t *testing.T) {
+	var comps, rentComps CompsResult
+	decodeFixture(t, compsPath, &comps)
+	decodeFixture(t, "GetCompsRent", &rentComps)
+	var deep, rentDeep DeepCompsResult
+	decodeFixture(t, deepCompsPath, &deep)
+	decodeFixture(t, "GetDeepCompsRent", &rentDeep)
+
+	for i, c := range comps.Comparables {
+		if c.RentZestimate != nil {
+			t.Errorf("comp %d: expected no rent zestimate but got %v", i, c.RentZestimate)
+		}
+	}
+	for i, c := range deep.Comparables {
+		if c.RentZestimate != nil {
+			t.Errorf("deep comp %d: expected no rent zestimate but got %v", i, c.RentZestimate)
+		}
+	}
+
+	for i, expected := range []Zestimate{
+		{Amount: Value{Currency: "USD", Value: 2900, Present: true}, Low: Value{Currency: "USD", Value: 2320, Present: true}, High: Value{Currency: "USD", Value: 3480, Present: true}},
+		{Amount: Value{Currency: "USD", Value: 2450, Present: true}, Low: Value{Currency: "USD", Value: 1960, Present: true}, High: Value{Currency: "USD", Value: 2940, Present: true}},
+	} {
+		expected.LastUpdated = "11/01/2009"
+		expected.ValueChange = ValueChange{Duration: 30, Currency: "USD"}
+		expected.Kind = RentZestimate
+		if r := rentComps.Comparables[i].RentZestimate; r == nil || *r != expected {
+			t.Errorf("comp %d: expected %+v but got %+v", i, expected, r)
+		}
+		// The sale zestimate is unaffected.
+		if rentComps.Comparables[i].Zestimate != comps.Comparables[i].Zestimate {
+			t.Errorf("comp %d: expected sale zestimate %+v but got %+v", i, comps.Comparables[i].Zestimate, rentComps.Comparables[i].Zestimate)
+		}
+	}
+	for i, amount := range []int{2950, 2300} {
+		if r := rentDeep.Comparables[i].RentZestimate; r == nil || r.Amount.Value != amount || r.Kind != RentZestimate {
+			t.Errorf("deep comp %d: expected rent zestimate of %d but got %+v", i, amount, r)
+		}
+	}
+}
+
+func TestCompsCountCap(t *testing.T) {
+	// A count over the maximum is lowered in the query.
+	for _, path := range []string{compsPath, deepCompsPath} {
+		server, client := testFixture(t, path, path, func(values url.Values) {
+			assertOnlyParam(t, values, countParam, strconv.Itoa(maxCompsCount))
+		})
+		var err error
+		if path == compsPath {
+			_, err = client.GetComps(CompsRequest{Zpid: zpid, Count: 30})
+		} else {
+			_, err = client.GetDeepComps(CompsRequest{Zpid: zpid, Count: 30})
+		}
+		server.Close()
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+
+	// Or rejected without a request.
+	server, _ := unreachable(t)
+	strict := NewExt(testZwsId, server.URL, WithStrictCompsCount())
+	for name, call := range map[string]func() error{
+		"GetComps": func() error {
+			_, err := strict.GetComps(CompsRequest{Zpid: zpid, Count: 30})
+			return err
+		},
+		"GetDeepComps": func() error {
+			_, err := strict.GetDeepComps(CompsRequest{Zpid: zpid, Count: 30})
+			return err
+		},
+	} {
+		var tooHigh *CountTooHighError
+		if err := call(); !errors.Is(err, ErrCountTooHigh) || !errors.As(err, &tooHigh) {
+			t.Errorf("%s: expected ErrCountTooHigh but got %v", name, err)
+		} else if *tooHigh != (CountTooHighError{Count: 30, Max: maxCompsCount}) {
+			t.Errorf("%s: unexpected error %+v", name, *tooHigh)
+		}
+	}
+	server.Close()
+
+	// If Zillow rejects the count anyway, the error is classified.
+	server, client := testFixture(t, compsPath, "GetCompsCountTooHigh", func(url.Values) {})
+	defer server.Close()
+	var tooHigh *CountTooHighError
+	if _, err := client.GetComps(CompsRequest{Zpid: zpid, Count: 30}); !errors.Is(err, ErrCountTooHigh) || !errors.As(err, &tooHigh) {
+		t.Errorf("expected ErrCountTooHigh but got %v", err)
+	} else if tooHigh.Count != maxCompsCount || tooHigh.Max != 0 || tooHigh.Text == "" {
+		t.Errorf("expected the rejected count and message but got %+v", *tooHigh)
+	}
+
+	// The same code for a bad zpid is left to the message.
+	server, client = testFixture(t, compsPath, "GetCompsInvalidZpid", func(url.Values) {})
+	defer server.Close()
+	result, err := client.GetComps(CompsRequest{Zpid: zpid, Count: count})
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if result.Message.Code != codeInvalidCount {
+		t.Errorf("expected code %d but got %#v", codeInvalidCount, result.Message)
+	}
+}
@@ -0,0 +1,341 @@
+package zillow
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// decodeXML decodes body into v, like decodeReader.
+func decodeXML(body []byte, v interface{}) error {
+	return decodeReader(bytes.NewReader(body), v)
+}
+
+// decodeReader decodes the XML read from r into v. Elements in a default
+// namespace declared with a bare xmlns attribute are decoded as if they had no
+// namespace, so namespaced and plain variants of a response decode
+// identically.
+func decodeReader(r io.Reader, v interface{}) error {
+	s := &namespaceStripper{d: xml.NewDecoder(r)}
+	if err := xml.NewTokenDecoder(s).Decode(v); err != nil {
+		return err
+	}
+	if r, ok := v.(interface{ setResponsePresent(bool) }); ok {
+		r.setResponsePresent(s.responsePresent)
+	}
+	return nil
+}
+
+// namespaceStripper is an xml.TokenReader which removes default namespaces.
+// It also notes whether the root element has a <response> child with any
+// content, as the tokens pass.
+type namespaceStripper struct {
+	d *xml.Decoder
+	// defaults is the stack of default namespaces in scope for open elements.
+	defaults []string
+	// inResponse is set within the root's <response> element.
+	inResponse      bool
+	responsePresent bool
+}
+
+func (s *namespaceStripper) Token() (xml.Token, error) {
+	t, err := s.d.Token()
+	if err != nil {
+		return t, err
+	}
+	switch e := t.(type) {
+	case xml.StartElement:
+		var ns string
+		if n := len(s.defaults); n > 0 {
+			ns = s.defaults[n-1]
+		}
+		attrs := make([]xml.Attr, 0, len(e.Attr))
+		for _, a := range e.Attr {
+			if a.Name.Space == "" && a.Name.Local == "xmlns" {
+				ns = a.Value
+				continue
+			}
+			attrs = append(attrs, a)
+		}
+		e.Attr = attrs
+		s.defaults = append(s.defaults, ns)
+		if ns != "" && e.Name.Space == ns {
+			e.Name.Space = ""
+		}
+		if s.inResponse {
+			s.responsePresent = true
+		}
+		s.inResponse = s.inResponse || len(s.defaults) == 2 && e.Name.Local == "response"
+		return e, nil
+	case xml.EndElement:
+		n := len(s.defaults)
+		if n == 0 {
+			return e, nil
+		}
+		if n == 2 {
+			s.inResponse = false
+		}
+		ns := s.defaults[n-1]
+		s.defaults = s.defaults[:n-1]
+		if ns != "" && e.Name.Space == ns {
+			e.Name.Space = ""
+		}
+		return e, nil
+	case xml.CharData:
+		if s.inResponse && len(bytes.TrimSpace(e)) > 0 {
+			s.responsePresent = true
+		}
+	}
+	return t, nil
+}
+
+func (z *zillow) GetDeepSearchResultsStream(ctx context.Context, request SearchRequest, fn func(DeepSearchResult) error) error {
+	if err := request.validate(); err != nil {
+		return err
+	}
+	stream := deepSearchStream{z: z, request: request, fn: fn}
+	if err := z.get(ctx, deepSearchPath, request, &stream); err != nil {
+		return err
+	} else if stream.err != nil {
+		return stream.err
+	} else if !stream.messageSeen {
+		return &DecodeError{Err: errors.New("no message element")}
+	} else {
+		return stream.Message.Err()
+	}
+}
+
+// deepSearchStream decodes a deep search response from the live body, passing
+// each result to fn as soon as it is decoded. With WithStrictSingleResult, the
+// first result is held back until the rest show it is the only one.
+type deepSearchStream struct {
+	z       *zillow
+	request SearchRequest
+	fn      func(DeepSearchResult) error
+	// delivered counts the results passed to fn, which a retried attempt
+	// skips rather than passing again.
+	delivered int
+	// err is the error returned by fn or the checks of the client, if any.
+	err error
+	// pending is the first result, held back under strict single results,
+	// and zpids are those of every result.
+	pending *DeepSearchResult
+	zpids   []string
+
+	Message     Message
+	messageSeen bool
+}
+
+func (s *deepSearchStream) incremental() {}
+
+// reset clears what an attempt decoded, but keeps fn and delivered.
+func (s *deepSearchStream) reset() {
+	s.Message = Message{}
+	s.messageSeen = false
+	s.pending = nil
+	s.zpids = nil
+}
+
+// UnmarshalXML decodes the request, message and results of the root element.
+// It skips the rest of the response once the message reports an error, or fn
+// or a check fails.
+func (s *deepSearchStream) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var results int
+	for depth := 1; depth > 0; {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch e := t.(type) {
+		case xml.StartElement:
+			switch e.Name.Local {
+			case "request":
+				var echoed SearchRequest
+				if err := d.DecodeElement(&echoed, &e); err != nil {
+					return err
+				}
+				if s.err = s.z.checkSearch(s.request, echoed, nil); s.err != nil {
+					return skipDepth(d, depth)
+				}
+			case "message":
+				if err := d.DecodeElement(&s.Message, &e); err != nil {
+					return err
+				}
+				s.messageSeen = true
+				if s.Message.Err() != nil {
+					return skipDepth(d, depth)
+				}
+			case "result":
+				results++
+				if results <= s.delivered {
+					if err := d.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				var r DeepSearchResult
+				if err := d.DecodeElement(&r, &e); err != nil {
+					return err
+				}
+				if s.z.strictSingleResult {
+					s.zpids = append(s.zpids, r.Zpid)
+					if s.pending == nil {
+						s.pending = &r
+					}
+					continue
+				}
+				if s.deliver(r) != nil {
+					return skipDepth(d, depth)
+				}
+			default:
+				depth++
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+	if s.pending != nil {
+		if s.err = s.z.checkSingleResult(s.zpids); s.err == nil {
+			s.deliver(*s.pending)
+		}
+	}
+	return nil
+}
+
+// deliver passes r to fn, and returns and records any error.
+func (s *deepSearchStream) deliver(r DeepSearchResult) error {
+	s.delivered++
+	s.err = s.fn(r)
+	return s.err
+}
+
+// skipDepth skips the rest of the depth innermost open elements.
+func skipDepth(d *xml.Decoder, depth int) error {
+	for ; depth > 0; depth-- {
+		if err := d.Skip(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partialDeepSearchResults is DeepSearchResults with each result decoded
+// separately.
+type partialDeepSearchResults struct {
+	XMLName xml.Name `xml:"searchresults"`
+	base
+
+	Request SearchRequest `xml:"request"`
+	Message Message       `xml:"message"`
+
+	Results []partialResult `xml:"response>results>result"`
+}
+
+// partialResult holds a DeepSearchResult or the error decoding it.
+type partialResult struct {
+	result DeepSearchResult
+	err    error
+}
+
+// UnmarshalXML buffers the tokens of the element and decodes them on their
+// own, so a malformed field fails only this result.
+func (p *partialResult) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	tokens := []xml.Token{start.Copy()}
+	for depth := 1; depth > 0; {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+		tokens = append(tokens, xml.CopyToken(t))
+	}
+	p.err = xml.NewTokenDecoder(&tokenSlice{tokens: tokens}).Decode(&p.result)
+	return nil
+}
+
+// tokenSlice is an xml.TokenReader over buffered tokens.
+type tokenSlice struct {
+	tokens []xml.Token
+}
+
+func (s *tokenSlice) Token() (xml.Token, error) {
+	if len(s.tokens) == 0 {
+		return nil, io.EOF
+	}
+	t := s.tokens[0]
+	s.tokens = s.tokens[1:]
+	return t, nil
+}
+
+// GetDeepSearchResultsPartial tolerates results with malformed fields, such as
+// non-numeric bedrooms. A body which isn't well-formed XML still fails the
+// call.
+func (z *zillow) GetDeepSearchResultsPartial(ctx context.Context, request SearchRequest) (*DeepSearchResults, []error, error) {
+	if err := request.validate(); err != nil {
+		return nil, nil, err
+	}
+	var partial partialDeepSearchResults
+	if err := z.get(ctx, deepSearchPath, request, &partial); err != nil {
+		return nil, nil, err
+	}
+	result := DeepSearchResults{
+		XMLName: partial.XMLName,
+		base:    partial.base,
+		Request: partial.Request,
+		Message: partial.Message,
+	}
+	var errs []error
+	for i, p := range partial.Results {
+		if p.err != nil {
+			errs = append(errs, &DecodeError{Err: fmt.Errorf("result %d: %w", i, p.err)})
+			continue
+		}
+		result.Results = append(result.Results, p.result)
+	}
+	if err := z.checkSearch(request, result.Request, deepResultZpids(result.Results)); err != nil {
+		return nil, nil, err
+	}
+	return &result, errs, nil
+}
+
+// MinimalDeepSearchResults is DeepSearchResults with only enough of each result
+// for a list view.
+type MinimalDeepSearchResults struct {
+	XMLName xml.Name `xml:"searchresults"`
+	base
+
+	Request SearchRequest `xml:"request"`
+	Message Message       `xml:"message"`
+
+	Results []MinimalResult `xml:"response>results>result"`
+}
+
+// MinimalResult is the zpid, address and Zestimate amount of a
+// DeepSearchResult.
+type MinimalResult struct {
+	Zpid      string  `xml:"zpid"`
+	Address   Address `xml:"address"`
+	Zestimate Value   `xml:"zestimate>amount"`
+}
+
+func (z *zillow) GetDeepSearchResultsMinimal(ctx context.Context, request SearchRequest) (*MinimalDeepSearchResults, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+	var result MinimalDeepSearchResults
+	if err := z.get(ctx, deepSearchPath, request, &result); err != nil {
+		return nil, err
+	} else if err := z.checkSearch(request, result.Request, minimalResultZpids(result.Results)); err != nil {
+		return nil, err
+	} else {
+		return &result, nil
+	}
+}
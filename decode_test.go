@@ -0,0 +1,586 @@
+package zillow
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodeDefaultNamespace(t *testing.T) {
+	var results []*ZestimateResult
+	for _, fixture := range []string{zestimatePath, "GetZestimateNamespaced"} {
+		server, zillow := testFixture(t, zestimatePath, fixture, func(url.Values) {})
+		result, err := zillow.GetZestimate(ZestimateRequest{Zpid: zpid})
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+	if !reflect.DeepEqual(results[0], results[1]) {
+		t.Fatalf("expected:\n %s\n\n but got:\n %s", prettyJSON(t, results[0]), prettyJSON(t, results[1]))
+	}
+
+	// Make sure the fixture actually exercises the default namespace.
+	b, err := ioutil.ReadFile("testdata/GetZestimateNamespaced.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plain ZestimateResult
+	if err := xml.Unmarshal(b, &plain); err != nil {
+		t.Fatal(err)
+	}
+	if plain.Links.XMLName.Space == "" {
+		t.Fatal("expected fixture elements to be namespaced")
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	for _, c := range []struct {
+		in       string
+		expected Decimal
+	}{
+		{"<taxAssessment>1,054,000</taxAssessment>", 1054000},
+		{"<taxAssessment>1054000.0</taxAssessment>", 1054000},
+		{"<taxAssessment> 804000.5 </taxAssessment>", 804000.5},
+		{"<taxAssessment></taxAssessment>", 0},
+		{"<taxAssessment/>", 0},
+	} {
+		var result struct {
+			TaxAssessment Decimal `xml:"taxAssessment"`
+		}
+		if err := xml.Unmarshal([]byte("<result>"+c.in+"</result>"), &result); err != nil {
+			t.Errorf("%s: %v", c.in, err)
+			continue
+		}
+		if result.TaxAssessment != c.expected {
+			t.Errorf("%s: expected %f but got %f", c.in, c.expected, result.TaxAssessment)
+		}
+	}
+
+	var result struct {
+		TaxAssessment Decimal `xml:"taxAssessment"`
+	}
+	if err := xml.Unmarshal([]byte("<result><taxAssessment>n/a</taxAssessment></result>"), &result); err == nil {
+		t.Error("expected error for non-numeric value")
+	}
+}
+
+func TestGetDeepSearchResultsStream(t *testing.T) {
+	server, zillow := testFixture(t, deepSearchPath, "GetDeepSearchResultsMulti", func(values url.Values) {
+		assertOnlyParam(t, values, addressParam, address)
+		assertOnlyParam(t, values, cityStateZipParam, citystatezip)
+	})
+	defer server.Close()
+	request := SearchRequest{Address: address, CityStateZip: citystatezip}
+
+	var zpids []string
+	err := zillow.GetDeepSearchResultsStream(context.Background(), request, func(r DeepSearchResult) error {
+		zpids = append(zpids, r.Zpid)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"48749425", "48749430", "48749445"}; !reflect.DeepEqual(zpids, expected) {
+		t.Fatalf("expected %v but got %v", expected, zpids)
+	}
+
+	// The streamed results match the ones decoded all at once.
+	var all DeepSearchResults
+	decodeFixture(t, "GetDeepSearchResultsMulti", &all)
+	var i int
+	stop := errors.New("stop")
+	err = zillow.GetDeepSearchResultsStream(context.Background(), request, func(r DeepSearchResult) error {
+		if !reflect.DeepEqual(r, all.Results[i]) {
+			t.Errorf("expected:\n %s\n\n but got:\n %s", prettyJSON(t, all.Results[i]), prettyJSON(t, r))
+		}
+		i++
+		if i == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("expected callback error but got %v", err)
+	}
+	if i != 2 {
+		t.Fatalf("expected stream to stop after 2 results but got %d", i)
+	}
+}
+
+func TestGetDeepSearchResultsStreamChecks(t *testing.T) {
+	fixture, err := ioutil.ReadFile("testdata/GetDeepSearchResultsMulti.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first response is cut off after the first result.
+	first := bytes.Index(fixture, []byte("</result>")) + len("</result>")
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.Write(fixture[:first])
+		default:
+			w.Write(fixture)
+		}
+	}))
+	defer server.Close()
+	request := SearchRequest{Address: address, CityStateZip: citystatezip}
+
+	z := &zillow{zwsId: testZwsId, url: server.URL}
+	WithRetry(2)(z)
+	var zpids []string
+	err = z.GetDeepSearchResultsStream(context.Background(), request, func(r DeepSearchResult) error {
+		zpids = append(zpids, r.Zpid)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls but got %d", calls)
+	}
+	if expected := []string{"48749425", "48749430", "48749445"}; !reflect.DeepEqual(zpids, expected) {
+		t.Fatalf("expected %v but got %v", expected, zpids)
+	}
+
+	for _, body := range []string{"", "<searchresults></searchresults>"} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, body)
+		}))
+		z := &zillow{zwsId: testZwsId, url: server.URL}
+		err := z.GetDeepSearchResultsStream(context.Background(), request, func(DeepSearchResult) error { return nil })
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Errorf("%q: expected *DecodeError but got %v", body, err)
+		}
+		server.Close()
+	}
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<html><body>Service Unavailable</body></html>")
+	}))
+	defer server.Close()
+	z = &zillow{zwsId: testZwsId, url: server.URL}
+	if err := z.GetDeepSearchResultsStream(context.Background(), request, func(DeepSearchResult) error { return nil }); !errors.Is(err, ErrNotXML) {
+		t.Errorf("expected ErrNotXML but got %v", err)
+	}
+
+	// Streamed responses are stored and replayed like any other.
+	dir, err := ioutil.TempDir("", "zillow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	record, client := testFixture(t, deepSearchPath, "GetDeepSearchResultsMulti", func(url.Values) {}, WithResponseStore(dir))
+	defer record.Close()
+	if err := client.GetDeepSearchResultsStream(context.Background(), request, func(DeepSearchResult) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	offline, replay := unreachable(t)
+	defer offline.Close()
+	WithReplayFrom(dir)(replay.(*zillow))
+	zpids = nil
+	err = replay.GetDeepSearchResultsStream(context.Background(), request, func(r DeepSearchResult) error {
+		zpids = append(zpids, r.Zpid)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zpids) != 3 {
+		t.Fatalf("expected 3 replayed results but got %v", zpids)
+	}
+}
+
+func TestGetDeepSearchResultsStreamIncremental(t *testing.T) {
+	fixture, err := ioutil.ReadFile("testdata/GetDeepSearchResultsMulti.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := bytes.Index(fixture, []byte("</result>")) + len("</result>")
+	// The rest of the body is only sent once the first result was passed on.
+	delivered := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(fixture[:first])
+		w.(http.Flusher).Flush()
+		select {
+		case <-delivered:
+		case <-time.After(5 * time.Second):
+			t.Error("expected the first result before the rest of the body")
+		}
+		w.Write(fixture[first:])
+	}))
+	defer server.Close()
+
+	z := &zillow{zwsId: testZwsId, url: server.URL}
+	var zpids []string
+	err = z.GetDeepSearchResultsStream(context.Background(), SearchRequest{Address: address, CityStateZip: citystatezip}, func(r DeepSearchResult) error {
+		if len(zpids) == 0 {
+			close(delivered)
+		}
+		zpids = append(zpids, r.Zpid)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zpids) != 3 {
+		t.Errorf("expected 3 results but got %v", zpids)
+	}
+}
+
+func TestGetDeepSearchResultsStreamOptions(t *testing.T) {
+	request := SearchRequest{Address: address, CityStateZip: citystatezip}
+	for _, c := range []struct {
+		name    string
+		fixture string
+		opt     Option
+		target  error
+	}{
+		{"strict single result", "GetDeepSearchResultsMulti", WithStrictSingleResult(), ErrAmbiguousAddress},
+		{"echo check", "GetSearchResultsNormalized", WithRequestEchoCheck(), ErrRequestMismatch},
+	} {
+		server, client := testFixture(t, deepSearchPath, c.fixture, func(url.Values) {}, c.opt)
+		var calls int
+		err := client.GetDeepSearchResultsStream(context.Background(), request, func(DeepSearchResult) error {
+			calls++
+			return nil
+		})
+		server.Close()
+		if !errors.Is(err, c.target) {
+			t.Errorf("%s: expected %v but got %v", c.name, c.target, err)
+		}
+		if calls != 0 {
+			t.Errorf("%s: expected no results passed on but got %d", c.name, calls)
+		}
+	}
+
+	// A single result still passes strict checks.
+	server, client := testFixture(t, deepSearchPath, deepSearchPath, func(url.Values) {}, WithStrictSingleResult())
+	defer server.Close()
+	var zpids []string
+	err := client.GetDeepSearchResultsStream(context.Background(), request, func(r DeepSearchResult) error {
+		zpids = append(zpids, r.Zpid)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zpids) != 1 {
+		t.Errorf("expected the single result but got %v", zpids)
+	}
+}
+
+func TestGetDeepSearchResultsMinimal(t *testing.T) {
+	server, zillow := testFixture(t, deepSearchPath, "GetDeepSearchResultsMulti", func(values url.Values) {
+		assertOnlyParam(t, values, addressParam, address)
+		assertOnlyParam(t, values, cityStateZipParam, citystatezip)
+	})
+	defer server.Close()
+
+	result, err := zillow.GetDeepSearchResultsMinimal(context.Background(), SearchRequest{Address: address, CityStateZip: citystatezip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.HasResponse() || result.Message.Code != 0 {
+		t.Fatalf("expected a successful response but got %+v", result)
+	}
+
+	// The minimal results match the full ones.
+	var all DeepSearchResults
+	decodeFixture(t, "GetDeepSearchResultsMulti", &all)
+	if len(result.Results) != len(all.Results) {
+		t.Fatalf("expected %d results but got %d", len(all.Results), len(result.Results))
+	}
+	for i, r := range all.Results {
+		expected := MinimalResult{Zpid: r.Zpid, Address: r.Address, Zestimate: r.Zestimate.Amount}
+		if result.Results[i] != expected {
+			t.Errorf("%d: expected %+v but got %+v", i, expected, result.Results[i])
+		}
+	}
+	if amount := result.Results[2].Zestimate.Value; amount != 985000 {
+		t.Errorf("expected zestimate 985000 but got %d", amount)
+	}
+
+	// The client's checks apply as they do to GetDeepSearchResults.
+	for _, c := range []struct {
+		name    string
+		fixture string
+		opt     Option
+		target  error
+	}{
+		{"strict single result", "GetDeepSearchResultsMulti", WithStrictSingleResult(), ErrAmbiguousAddress},
+		{"echo check", "GetSearchResultsNormalized", WithRequestEchoCheck(), ErrRequestMismatch},
+	} {
+		server, client := testFixture(t, deepSearchPath, c.fixture, func(url.Values) {}, c.opt)
+		result, err := client.GetDeepSearchResultsMinimal(context.Background(), SearchRequest{Address: address, CityStateZip: citystatezip})
+		server.Close()
+		if !errors.Is(err, c.target) || result != nil {
+			t.Errorf("%s: expected %v but got %v", c.name, c.target, err)
+		}
+	}
+}
+
+func BenchmarkDecodeDeepSearchResults(b *testing.B) {
+	body, err := ioutil.ReadFile("testdata/GetDeepSearchResultsMulti.xml")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("full", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var result DeepSearchResults
+			if err := decodeXML(body, &result); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("minimal", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var result MinimalDeepSearchResults
+			if err := decodeXML(body, &result); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestHasResponse(t *testing.T) {
+	var empty ZestimateResult
+	decodeFixture(t, "GetZestimateEmptyResponse", &empty)
+	if empty.HasResponse() {
+		t.Error("expected no response for empty <response/>")
+	}
+	if empty.Address != (Address{}) {
+		t.Errorf("expected zero address but got %#v", empty.Address)
+	}
+
+	var full ZestimateResult
+	decodeFixture(t, zestimatePath, &full)
+	if !full.HasResponse() {
+		t.Error("expected response")
+	}
+
+	var missing ZestimateResult
+	decodeFixture(t, "GetZestimateNoMatch", &missing)
+	if missing.HasResponse() {
+		t.Error("expected no response when <response> is absent")
+	}
+}
+
+// TestDecodeZestimateNesting decodes the same <zestimate> under each parent it
+// appears under and expects identical values.
+func TestDecodeZestimateNesting(t *testing.T) {
+	const zestimate = `<zestimate>
+	<amount currency="USD">1219500</amount>
+	<last-updated>11/03/2009</last-updated>
+	<valueChange duration="30" currency="USD">-41500</valueChange>
+	<valuationRange>
+		<low currency="USD">1024380</low>
+		<high currency="USD">1378035</high>
+	</valuationRange>
+	<percentile>95</percentile>
+</zestimate>`
+	expected := Zestimate{
+		Amount:      Value{Currency: "USD", Value: 1219500, Present: true},
+		LastUpdated: "11/03/2009",
+		ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: -41500},
+		Low:         Value{Currency: "USD", Value: 1024380, Present: true},
+		High:        Value{Currency: "USD", Value: 1378035, Present: true},
+		Percentile:  "95",
+	}
+	search := `<searchresults><response><results><result>` + zestimate + `</result></results></response></searchresults>`
+	comps := `<comps><response><properties><principal>` + zestimate + `</principal>` +
+		`<comparables><comp score="1.0">` + zestimate + `</comp></comparables></properties></response></comps>`
+
+	for _, c := range []struct {
+		name      string
+		body      string
+		result    interface{}
+		zestimate func(result interface{}) []Zestimate
+	}{
+		{"ZestimateResult", `<zestimate><response>` + zestimate + `</response></zestimate>`, &ZestimateResult{}, func(r interface{}) []Zestimate {
+			return []Zestimate{r.(*ZestimateResult).Zestimate}
+		}},
+		{"SearchResult", search, &SearchResults{}, func(r interface{}) []Zestimate {
+			var zs []Zestimate
+			for _, result := range r.(*SearchResults).Results {
+				zs = append(zs, result.Zestimate)
+			}
+			return zs
+		}},
+		{"DeepSearchResult", search, &DeepSearchResults{}, func(r interface{}) []Zestimate {
+			var zs []Zestimate
+			for _, result := range r.(*DeepSearchResults).Results {
+				zs = append(zs, result.Zestimate)
+			}
+			return zs
+		}},
+		{"Principal and Comp", comps, &CompsResult{}, func(r interface{}) []Zestimate {
+			result := r.(*CompsResult)
+			zs := []Zestimate{result.Principal.Zestimate}
+			for _, comp := range result.Comparables {
+				zs = append(zs, comp.Zestimate)
+			}
+			return zs
+		}},
+		{"DeepPrincipal and DeepComp", comps, &DeepCompsResult{}, func(r interface{}) []Zestimate {
+			result := r.(*DeepCompsResult)
+			zs := []Zestimate{result.Principal.Zestimate}
+			for _, comp := range result.Comparables {
+				zs = append(zs, comp.Zestimate)
+			}
+			return zs
+		}},
+	} {
+		if err := decodeXML([]byte(c.body), c.result); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		zs := c.zestimate(c.result)
+		if len(zs) == 0 {
+			t.Errorf("%s: no zestimates decoded", c.name)
+		}
+		for _, z := range zs {
+			if z != expected {
+				t.Errorf("%s: expected %+v but got %+v", c.name, expected, z)
+			}
+		}
+	}
+}
+
+func TestGetDeepSearchResultsPartial(t *testing.T) {
+	server, zillow := testFixture(t, deepSearchPath, "GetDeepSearchResultsMalformed", func(url.Values) {})
+	defer server.Close()
+	request := SearchRequest{Address: address, CityStateZip: citystatezip}
+
+	if _, err := zillow.GetDeepSearchResults(request); err == nil {
+		t.Fatal("expected the malformed result to fail a full decode")
+	}
+
+	result, errs, err := zillow.GetDeepSearchResultsPartial(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all DeepSearchResults
+	decodeFixture(t, "GetDeepSearchResultsMulti", &all)
+	expected := []DeepSearchResult{all.Results[0], all.Results[2]}
+	if !reflect.DeepEqual(result.Results, expected) {
+		t.Errorf("expected:\n %s\n\n but got:\n %s", prettyJSON(t, expected), prettyJSON(t, result.Results))
+	}
+	if result.Message != all.Message || result.Request != all.Request || !result.HasResponse() {
+		t.Errorf("expected the rest of the response to decode but got %+v", result)
+	}
+	var decodeErr *DecodeError
+	if len(errs) != 1 || !errors.As(errs[0], &decodeErr) {
+		t.Fatalf("expected a single DecodeError but got %v", errs)
+	}
+
+	// The client's checks apply to the surviving results.
+	for _, c := range []struct {
+		name    string
+		fixture string
+		opt     Option
+		target  error
+	}{
+		{"strict single result", "GetDeepSearchResultsMalformed", WithStrictSingleResult(), ErrAmbiguousAddress},
+		{"echo check", "GetSearchResultsNormalized", WithRequestEchoCheck(), ErrRequestMismatch},
+	} {
+		server, client := testFixture(t, deepSearchPath, c.fixture, func(url.Values) {}, c.opt)
+		result, _, err := client.GetDeepSearchResultsPartial(context.Background(), request)
+		server.Close()
+		if !errors.Is(err, c.target) || result != nil {
+			t.Errorf("%s: expected %v but got %v", c.name, c.target, err)
+		}
+	}
+}
+
+// TestErrorOnlyResponse decodes a body with just a failure message, as Zillow
+// sends for errors, for every endpoint.
+func TestErrorOnlyResponse(t *testing.T) {
+	for _, e := range endpoints {
+		t.Run(e.path, func(t *testing.T) {
+			root := fixtureRoot(t, e.path)
+			for _, body := range []string{
+				errorBody(root, 507),
+				fmt.Sprintf(`<Z:%s xmlns:Z="http://www.zillow.com/static/xsd/Z.xsd"><request/><message><text>error</text><code>507</code></message></Z:%s>`, root, root),
+			} {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					io.WriteString(w, body)
+				}))
+				result, err := e.call(&zillow{zwsId: testZwsId, url: server.URL})
+				server.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var apiErr *APIError
+				if err := messageErr(result); !errors.As(err, &apiErr) || apiErr.Code != 507 || apiErr.Text != "error" {
+					t.Errorf("%s: expected code 507 message but got %v", body, err)
+				}
+				if result.(interface{ HasResponse() bool }).HasResponse() {
+					t.Errorf("%s: expected no response", body)
+				}
+			}
+		})
+	}
+}
+
+// fixtureRoot returns the local name of the root element of the fixture.
+func fixtureRoot(t *testing.T, fixture string) string {
+	f, err := os.Open("testdata/" + fixture + ".xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := xml.NewDecoder(f)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local
+		}
+	}
+}
+
+func TestDisclaimer(t *testing.T) {
+	server, zillow := testFixture(t, zestimatePath, "GetZestimateDisclaimer", func(url.Values) {})
+	defer server.Close()
+	result, err := zillow.GetZestimate(ZestimateRequest{Zpid: zpid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Zestimates are provided by Zillow and are not appraisals. See zillow.com/zestimate for details."
+	if result.Disclaimer != expected {
+		t.Errorf("expected %q but got %q", expected, result.Disclaimer)
+	}
+
+	var details UpdatedPropertyDetails
+	if err := decodeXML([]byte(`<updatedPropertyDetails><disclaimer>d</disclaimer></updatedPropertyDetails>`), &details); err != nil {
+		t.Fatal(err)
+	}
+	if details.Disclaimer != "d" {
+		t.Errorf("expected disclaimer but got %q", details.Disclaimer)
+	}
+
+	var none ZestimateResult
+	decodeFixture(t, zestimatePath, &none)
+	if none.Disclaimer != "" {
+		t.Errorf("expected no disclaimer but got %q", none.Disclaimer)
+	}
+}
@@ -0,0 +1,281 @@
+package zillow
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// DecodeError is returned when a response body can't be read or decoded.
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return "zillow: decoding response: " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the body was truncated, e.g. by a connection
+// dropped mid-response, so that repeating the call may succeed.
+func (e *DecodeError) Retryable() bool {
+	if errors.Is(e.Err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var syntax *xml.SyntaxError
+	return errors.As(e.Err, &syntax) && syntax.Msg == "unexpected EOF"
+}
+
+// ErrNoRentZestimate is returned by GetRentZestimate when the response doesn't
+// include a rent Zestimate.
+var ErrNoRentZestimate = errors.New("zillow: no rent zestimate in response")
+
+// APIError is a non-zero message code reported by Zillow.
+type APIError struct {
+	Code int
+	Text string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("zillow: %s (code %d)", e.Text, e.Code)
+}
+
+// Retryable reports whether the code is a documented transient failure: a
+// service error or the service being unavailable.
+func (e *APIError) Retryable() bool {
+	return e.Code == codeServiceError || e.Code == codeServiceUnavailable
+}
+
+// ErrNotEntitled is matched by an *APIError reporting that the account isn't
+// entitled to call the endpoint, as for deep property data without the
+// corresponding entitlement. Unlike a no-match code, the request itself may be
+// fine.
+var ErrNotEntitled = errors.New("zillow: account not entitled to endpoint")
+
+// ErrInvalidZWSID is matched by an *APIError reporting that the ZWSID is
+// invalid or missing.
+var ErrInvalidZWSID = errors.New("zillow: invalid ZWSID")
+
+// ErrNoMatch is matched by an *APIError reporting that nothing matches the
+// request, such as an unknown zpid or address, and is returned by
+// ValueProperty when a search finds no property.
+var ErrNoMatch = errors.New("zillow: no match")
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotEntitled:
+		return e.Code == codeNotEntitled
+	case ErrInvalidZWSID:
+		return e.Code == codeInvalidZWSID
+	case ErrNoMatch:
+		return statusKeys[e.Code] == "no_match"
+	}
+	return false
+}
+
+// Err returns an *APIError if the message reports a failure, or nil.
+func (m Message) Err() error {
+	if m.Code == 0 {
+		return nil
+	}
+	return &APIError{Code: m.Code, Text: m.Text}
+}
+
+// messageErr returns the Err of the Message field of result, a pointer to a
+// result struct, or nil if it has none.
+func messageErr(result interface{}) error {
+	f := reflect.ValueOf(result).Elem().FieldByName("Message")
+	if !f.IsValid() {
+		return nil
+	}
+	if m, ok := f.Interface().(Message); ok {
+		return m.Err()
+	}
+	return nil
+}
+
+// Message codes shared by all endpoints. Codes of 500 and above are endpoint specific.
+const (
+	codeOK                 = 0
+	codeServiceError       = 1
+	codeInvalidZWSID       = 2
+	codeServiceUnavailable = 3
+	// codeNotEntitled is reported when the account may not call the endpoint,
+	// e.g. deep property data without the corresponding entitlement.
+	codeNotEntitled = 4
+	// codeNoResults is reported when nothing matches the request, e.g. an unknown zpid.
+	codeNoResults = 502
+)
+
+// statusKeys maps message codes to stable identifiers. Codes 507 and 508 are
+// the no-match codes of the search and comps endpoints.
+var statusKeys = map[int]string{
+	codeOK:                 "ok",
+	codeServiceError:       "service_error",
+	codeInvalidZWSID:       "invalid_zwsid",
+	codeServiceUnavailable: "service_unavailable",
+	codeNotEntitled:        "not_entitled",
+	codeNoResults:          "no_match",
+	507:                    "no_match",
+	508:                    "no_match",
+}
+
+// StatusKey returns a stable identifier for the message code, e.g. "ok" or
+// "no_match", suitable as a localization key. Unlike Text, it doesn't depend
+// on Zillow's wording. Unrecognized codes are "unknown".
+func (m Message) StatusKey() string {
+	if key, ok := statusKeys[m.Code]; ok {
+		return key
+	}
+	return "unknown"
+}
+
+// ErrCurrencyMismatch is returned by Value arithmetic on values in different
+// currencies.
+var ErrCurrencyMismatch = errors.New("zillow: currency mismatch")
+
+// ErrRequestMismatch is matched by a *RequestMismatchError.
+var ErrRequestMismatch = errors.New("zillow: echoed request does not match")
+
+// RequestMismatchError is returned when WithRequestEchoCheck is enabled and the
+// request echoed by Zillow differs from the one sent.
+type RequestMismatchError struct {
+	// Param is the query parameter which differs.
+	Param  string
+	Sent   string
+	Echoed string
+}
+
+func (e *RequestMismatchError) Error() string {
+	return fmt.Sprintf("zillow: echoed %s %q does not match sent %q", e.Param, e.Echoed, e.Sent)
+}
+
+func (e *RequestMismatchError) Is(target error) bool {
+	return target == ErrRequestMismatch
+}
+
+// ErrInsufficientComps is matched by an *InsufficientCompsError.
+var ErrInsufficientComps = errors.New("zillow: insufficient comparables")
+
+// InsufficientCompsError is returned when WithMinComps is set and a comps call
+// returns fewer comparables than the minimum.
+type InsufficientCompsError struct {
+	Found int
+	Min   int
+}
+
+func (e *InsufficientCompsError) Error() string {
+	return fmt.Sprintf("zillow: found %d comparables, need at least %d", e.Found, e.Min)
+}
+
+func (e *InsufficientCompsError) Is(target error) bool {
+	return target == ErrInsufficientComps
+}
+
+// ErrCountTooHigh is matched by a *CountTooHighError.
+var ErrCountTooHigh = errors.New("zillow: count too high")
+
+// CountTooHighError is returned by GetComps and GetDeepComps for a Count over
+// the maximum of 25 with WithStrictCompsCount, or when Zillow rejects the
+// count anyway.
+type CountTooHighError struct {
+	Count int
+	// Max is the maximum the Count exceeded, or 0 if Zillow rejected it.
+	Max int
+	// Text is the message text if Zillow rejected the Count.
+	Text string
+}
+
+func (e *CountTooHighError) Error() string {
+	if e.Max > 0 {
+		return fmt.Sprintf("zillow: count %d exceeds the maximum of %d", e.Count, e.Max)
+	}
+	return fmt.Sprintf("zillow: count %d rejected: %s", e.Count, e.Text)
+}
+
+func (e *CountTooHighError) Is(target error) bool {
+	return target == ErrCountTooHigh
+}
+
+// ErrAmbiguousAddress is matched by an *AmbiguousAddressError.
+var ErrAmbiguousAddress = errors.New("zillow: ambiguous address")
+
+// AmbiguousAddressError is returned when WithStrictSingleResult is set and a
+// search returns more than one result.
+type AmbiguousAddressError struct {
+	// Zpids are the candidates, in the order Zillow returned them.
+	Zpids []string
+}
+
+func (e *AmbiguousAddressError) Error() string {
+	return fmt.Sprintf("zillow: ambiguous address matched %d properties: %s", len(e.Zpids), strings.Join(e.Zpids, ", "))
+}
+
+func (e *AmbiguousAddressError) Is(target error) bool {
+	return target == ErrAmbiguousAddress
+}
+
+// ErrUnknownDate is matched by an *UnknownDateError.
+var ErrUnknownDate = errors.New("zillow: unknown date")
+
+// UnknownDateError is returned when parsing a date which is missing or the
+// epoch sentinel (see IsEpochSentinel), rather than malformed.
+type UnknownDateError struct {
+	Date string
+}
+
+func (e *UnknownDateError) Error() string {
+	if e.Date == "" {
+		return "zillow: missing date"
+	}
+	return fmt.Sprintf("zillow: unknown date %s", e.Date)
+}
+
+func (e *UnknownDateError) Is(target error) bool {
+	return target == ErrUnknownDate
+}
+
+// ErrNotXML is matched by a *NotXMLError.
+var ErrNotXML = errors.New("zillow: response is not XML")
+
+// NotXMLError is returned when Zillow responds with an HTML page instead of
+// XML, as it may for a disabled account. It usually indicates an account or
+// authorization problem rather than a malformed response.
+type NotXMLError struct {
+	// Snippet is the start of the body.
+	Snippet string
+}
+
+func (e *NotXMLError) Error() string {
+	return fmt.Sprintf("zillow: response is HTML, not XML: %q", e.Snippet)
+}
+
+func (e *NotXMLError) Is(target error) bool {
+	return target == ErrNotXML
+}
+
+// maxSnippet is the length of NotXMLError.Snippet.
+const maxSnippet = 200
+
+// checkNotHTML returns a *NotXMLError if body looks like an HTML page.
+func checkNotHTML(body []byte) error {
+	b := bytes.TrimSpace(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")))
+	if !hasPrefixFold(b, "<!doctype html") && !hasPrefixFold(b, "<html") {
+		return nil
+	}
+	if len(b) > maxSnippet {
+		b = b[:maxSnippet]
+	}
+	return &NotXMLError{Snippet: string(b)}
+}
+
+func hasPrefixFold(b []byte, prefix string) bool {
+	return len(b) >= len(prefix) && bytes.EqualFold(b[:len(prefix)], []byte(prefix))
+}
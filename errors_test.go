@@ -0,0 +1,81 @@
+package zillow
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestMessageStatusKey(t *testing.T) {
+	for code, expected := range map[int]string{
+		0:   "ok",
+		1:   "service_error",
+		2:   "invalid_zwsid",
+		3:   "service_unavailable",
+		4:   "not_entitled",
+		502: "no_match",
+		508: "no_match",
+		999: "unknown",
+	} {
+		if actual := (Message{Code: code}).StatusKey(); actual != expected {
+			t.Errorf("code %d: expected %q but got %q", code, expected, actual)
+		}
+	}
+
+	var result ZestimateResult
+	decodeFixture(t, "GetZestimateNoMatch", &result)
+	if actual := result.Message.StatusKey(); actual != "no_match" {
+		t.Errorf("expected %q but got %q", "no_match", actual)
+	}
+}
+
+func TestHTMLErrorPage(t *testing.T) {
+	for _, page := range []string{
+		"<!DOCTYPE html>\n<html><head><title>Account Disabled</title></head><body>Your account has been disabled.</body></html>",
+		"\n  <HTML><body>Your account has been disabled.</body></HTML>",
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, page)
+		}))
+		_, err := (&zillow{zwsId: testZwsId, url: server.URL}).GetZestimate(ZestimateRequest{Zpid: zpid})
+		server.Close()
+
+		if !errors.Is(err, ErrNotXML) {
+			t.Fatalf("expected ErrNotXML but got %v", err)
+		}
+		var notXML *NotXMLError
+		if !errors.As(err, &notXML) || !strings.Contains(notXML.Snippet, "Your account has been disabled.") {
+			t.Fatalf("expected snippet of the page but got %v", err)
+		}
+		var decodeErr *DecodeError
+		if errors.As(err, &decodeErr) {
+			t.Fatalf("expected not to be a DecodeError: %v", err)
+		}
+	}
+}
+
+func TestErrNotEntitled(t *testing.T) {
+	server, zillow := testFixture(t, deepCompsPath, "GetDeepCompsNotEntitled", func(url.Values) {})
+	defer server.Close()
+
+	result, err := zillow.GetDeepComps(CompsRequest{Zpid: zpid, Count: count})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := result.Message.Err(); !errors.Is(err, ErrNotEntitled) {
+		t.Fatalf("expected ErrNotEntitled but got %v", err)
+	}
+
+	var noMatch ZestimateResult
+	decodeFixture(t, "GetZestimateNoMatch", &noMatch)
+	if err := noMatch.Message.Err(); err == nil || errors.Is(err, ErrNotEntitled) {
+		t.Fatalf("expected a no-match error not to be ErrNotEntitled but got %v", err)
+	}
+	if err := noMatch.Message.Err(); !errors.Is(err, ErrNoMatch) {
+		t.Fatalf("expected ErrNoMatch but got %v", err)
+	}
+}
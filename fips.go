@@ -0,0 +1,138 @@
+package zillow
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// stateFIPS maps state abbreviations to FIPS state codes, for the states in
+// countyNames.
+var stateFIPS = map[string]string{
+	"AZ": "04",
+	"CA": "06",
+	"CO": "08",
+	"DC": "11",
+	"FL": "12",
+	"GA": "13",
+	"IL": "17",
+	"MA": "25",
+	"MI": "26",
+	"MN": "27",
+	"NV": "32",
+	"NY": "36",
+	"OR": "41",
+	"PA": "42",
+	"TX": "48",
+	"WA": "53",
+}
+
+// countyNames maps full five digit FIPS county codes to county names. It
+// covers every Washington county and the main county of the largest metros
+// elsewhere; other counties are not found.
+var countyNames = map[string]string{
+	// Washington
+	"53001": "Adams",
+	"53003": "Asotin",
+	"53005": "Benton",
+	"53007": "Chelan",
+	"53009": "Clallam",
+	"53011": "Clark",
+	"53013": "Columbia",
+	"53015": "Cowlitz",
+	"53017": "Douglas",
+	"53019": "Ferry",
+	"53021": "Franklin",
+	"53023": "Garfield",
+	"53025": "Grant",
+	"53027": "Grays Harbor",
+	"53029": "Island",
+	"53031": "Jefferson",
+	"53033": "King",
+	"53035": "Kitsap",
+	"53037": "Kittitas",
+	"53039": "Klickitat",
+	"53041": "Lewis",
+	"53043": "Lincoln",
+	"53045": "Mason",
+	"53047": "Okanogan",
+	"53049": "Pacific",
+	"53051": "Pend Oreille",
+	"53053": "Pierce",
+	"53055": "San Juan",
+	"53057": "Skagit",
+	"53059": "Skamania",
+	"53061": "Snohomish",
+	"53063": "Spokane",
+	"53065": "Stevens",
+	"53067": "Thurston",
+	"53069": "Wahkiakum",
+	"53071": "Walla Walla",
+	"53073": "Whatcom",
+	"53075": "Whitman",
+	"53077": "Yakima",
+
+	// Major metros
+	"04013": "Maricopa",
+	"06001": "Alameda",
+	"06037": "Los Angeles",
+	"06059": "Orange",
+	"06065": "Riverside",
+	"06067": "Sacramento",
+	"06071": "San Bernardino",
+	"06073": "San Diego",
+	"06075": "San Francisco",
+	"06085": "Santa Clara",
+	"08031": "Denver",
+	"11001": "District of Columbia",
+	"12011": "Broward",
+	"12086": "Miami-Dade",
+	"13121": "Fulton",
+	"17031": "Cook",
+	"25025": "Suffolk",
+	"26163": "Wayne",
+	"27053": "Hennepin",
+	"32003": "Clark",
+	"36005": "Bronx",
+	"36047": "Kings",
+	"36061": "New York",
+	"36081": "Queens",
+	"36085": "Richmond",
+	"41051": "Multnomah",
+	"41067": "Washington",
+	"42101": "Philadelphia",
+	"48029": "Bexar",
+	"48113": "Dallas",
+	"48201": "Harris",
+	"48453": "Travis",
+}
+
+// FIPS returns the full five digit FIPS county code, combining the state code
+// of Address.State with FIPSCounty. It is false if FIPSCounty is missing, or
+// is a bare county code for a state not covered by CountyName.
+func (r *DeepSearchResult) FIPS() (string, bool) {
+	county, err := strconv.Atoi(r.FIPSCounty)
+	if err != nil || county <= 0 {
+		return "", false
+	}
+	if len(r.FIPSCounty) == 5 {
+		// Already a full code.
+		return r.FIPSCounty, true
+	}
+	state, ok := stateFIPS[r.Address.State]
+	if !ok || county > 999 {
+		return "", false
+	}
+	return fmt.Sprintf("%s%03d", state, county), true
+}
+
+// CountyName returns the name of the county, without a "County" suffix, e.g.
+// "King". It is false if the county isn't in the built-in table, which covers
+// Washington and the largest metros elsewhere.
+func (r *DeepSearchResult) CountyName() (string, bool) {
+	fips, ok := r.FIPS()
+	if !ok {
+		return "", false
+	}
+	name, ok := countyNames[fips]
+	return name, ok
+}
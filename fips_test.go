@@ -0,0 +1,33 @@
+package zillow
+
+import "testing"
+
+func TestDeepSearchResultCountyName(t *testing.T) {
+	var result DeepSearchResults
+	decodeFixture(t, deepSearchPath, &result)
+	r := result.Results[0]
+
+	if fips, ok := r.FIPS(); !ok || fips != "53033" {
+		t.Errorf("expected FIPS 53033 but got %q, %t", fips, ok)
+	}
+	if name, ok := r.CountyName(); !ok || name != "King" {
+		t.Errorf("expected King but got %q, %t", name, ok)
+	}
+
+	for _, c := range []struct {
+		county, state, name string
+		ok                  bool
+	}{
+		{"53033", "", "King", true},
+		{"37", "CA", "Los Angeles", true},
+		{"33", "VT", "", false},
+		{"", "WA", "", false},
+		{"abc", "WA", "", false},
+		{"999", "WA", "", false},
+	} {
+		r := DeepSearchResult{FIPSCounty: c.county, Address: Address{State: c.state}}
+		if name, ok := r.CountyName(); name != c.name || ok != c.ok {
+			t.Errorf("%s/%s: expected %q, %t but got %q, %t", c.county, c.state, c.name, c.ok, name, ok)
+		}
+	}
+}
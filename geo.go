@@ -0,0 +1,174 @@
+package zillow
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// LatFloat parses Latitude.
+func (a Address) LatFloat() (float64, error) {
+	return parseCoordinate("latitude", a.Latitude)
+}
+
+// LngFloat parses Longitude.
+func (a Address) LngFloat() (float64, error) {
+	return parseCoordinate("longitude", a.Longitude)
+}
+
+// Coordinates parses Latitude and Longitude.
+func (a Address) Coordinates() (lat, lng float64, err error) {
+	return parseCoordinates(a.Latitude, a.Longitude)
+}
+
+// LatFloat parses Latitude.
+func (r Region) LatFloat() (float64, error) {
+	return parseCoordinate("latitude", r.Latitude)
+}
+
+// LngFloat parses Longitude.
+func (r Region) LngFloat() (float64, error) {
+	return parseCoordinate("longitude", r.Longitude)
+}
+
+// Coordinates parses Latitude and Longitude.
+func (r Region) Coordinates() (lat, lng float64, err error) {
+	return parseCoordinates(r.Latitude, r.Longitude)
+}
+
+func parseCoordinates(lat, lng string) (float64, float64, error) {
+	la, err := parseCoordinate("latitude", lat)
+	if err != nil {
+		return 0, 0, err
+	}
+	ln, err := parseCoordinate("longitude", lng)
+	if err != nil {
+		return 0, 0, err
+	}
+	return la, ln, nil
+}
+
+func parseCoordinate(name, s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("zillow: invalid %s %q", name, s)
+	}
+	return f, nil
+}
+
+// IsGeocoded reports whether Zillow located the address: both Latitude and
+// Longitude are set and valid.
+func (a Address) IsGeocoded() bool {
+	_, _, err := a.Coordinates()
+	return err == nil
+}
+
+// UngeocodedComps returns the comparables whose address isn't geocoded, which
+// can't be placed on a map.
+func (r *DeepCompsResult) UngeocodedComps() []DeepComp {
+	var comps []DeepComp
+	for _, c := range r.Comparables {
+		if !c.Address.IsGeocoded() {
+			comps = append(comps, c)
+		}
+	}
+	return comps
+}
+
+// earthRadiusMiles is the mean radius of the Earth.
+const earthRadiusMiles = 3958.8
+
+// haversineMiles returns the great-circle distance between two points.
+func haversineMiles(lat1, lng1, lat2, lng2 float64) float64 {
+	rad1, rad2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dLat, dLng := rad2-rad1, (lng2-lng1)*math.Pi/180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(rad1)*math.Cos(rad2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMiles * math.Asin(math.Sqrt(h))
+}
+
+// CompsWithinMiles returns the comparables within miles of the principal, by
+// great-circle distance. Comparables which aren't geocoded are skipped, and
+// none are returned if the principal isn't geocoded.
+func (r *DeepCompsResult) CompsWithinMiles(miles float64) []DeepComp {
+	lat, lng, err := r.Principal.Address.Coordinates()
+	if err != nil {
+		return nil
+	}
+	var comps []DeepComp
+	for _, c := range r.Comparables {
+		cLat, cLng, err := c.Address.Coordinates()
+		if err != nil {
+			continue
+		}
+		if haversineMiles(lat, lng, cLat, cLng) <= miles {
+			comps = append(comps, c)
+		}
+	}
+	return comps
+}
+
+// geoJSONFeature is a GeoJSON Feature with a Point geometry.
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONPoint           `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONPoint struct {
+	Type string `json:"type"`
+	// Coordinates are longitude then latitude.
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+// newGeoJSONFeature returns a feature at address, with the zpid, address and
+// Zestimate amount as properties. It fails if address isn't geocoded.
+func newGeoJSONFeature(zpid string, address Address, zestimate Zestimate) (geoJSONFeature, error) {
+	lat, lng, err := address.Coordinates()
+	if err != nil {
+		return geoJSONFeature{}, err
+	}
+	return geoJSONFeature{
+		Type:     "Feature",
+		Geometry: geoJSONPoint{Type: "Point", Coordinates: [2]float64{lng, lat}},
+		Properties: map[string]interface{}{
+			"zpid":      zpid,
+			"street":    address.Street,
+			"city":      address.City,
+			"state":     address.State,
+			"zipcode":   address.Zipcode,
+			"zestimate": zestimate.Amount.Value,
+			"currency":  zestimate.Amount.Currency,
+		},
+	}, nil
+}
+
+// GeoJSONFeature returns the property as a GeoJSON Feature with a Point
+// geometry, and the zpid, address and Zestimate amount as properties. It fails
+// if the address isn't geocoded.
+func (r *ZestimateResult) GeoJSONFeature() ([]byte, error) {
+	f, err := newGeoJSONFeature(r.Zpid, r.Address, r.Zestimate)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(f)
+}
+
+// GeoJSON returns the results as a GeoJSON FeatureCollection of features like
+// ZestimateResult.GeoJSONFeature. Results which aren't geocoded are skipped.
+func (r *SearchResults) GeoJSON() ([]byte, error) {
+	c := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	for _, result := range r.Results {
+		f, err := newGeoJSONFeature(result.Zpid, result.Address, result.Zestimate)
+		if err != nil {
+			continue
+		}
+		c.Features = append(c.Features, f)
+	}
+	return json.Marshal(c)
+}
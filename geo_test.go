@@ -0,0 +1,164 @@
+package zillow
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRegionCoordinates(t *testing.T) {
+	var result RegionChildren
+	decodeFixture(t, regionChildrenPath, &result)
+
+	expected := map[string][2]float64{
+		"Alki":      {47.56955, -122.397729},
+		"Greenwood": {47.694114, -122.355228},
+	}
+	for _, r := range result.Regions {
+		e, ok := expected[r.Name]
+		if !ok {
+			continue
+		}
+		delete(expected, r.Name)
+		lat, lng, err := r.Coordinates()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lat != e[0] || lng != e[1] {
+			t.Errorf("%s: expected %v but got %v, %v", r.Name, e, lat, lng)
+		}
+		if lat, err := r.LatFloat(); err != nil || lat != e[0] {
+			t.Errorf("%s: expected latitude %v but got %v, %v", r.Name, e[0], lat, err)
+		}
+		if lng, err := r.LngFloat(); err != nil || lng != e[1] {
+			t.Errorf("%s: expected longitude %v but got %v, %v", r.Name, e[1], lng, err)
+		}
+	}
+	if len(expected) > 0 {
+		t.Fatalf("regions not found: %v", expected)
+	}
+}
+
+func TestAddressCoordinates(t *testing.T) {
+	var result ZestimateResult
+	decodeFixture(t, zestimatePath, &result)
+	if lat, lng, err := result.Address.Coordinates(); err != nil || lat != 47.63793 || lng != -122.347936 {
+		t.Errorf("expected 47.63793, -122.347936 but got %v, %v, %v", lat, lng, err)
+	}
+
+	if _, _, err := (Address{Latitude: "47.6"}).Coordinates(); err == nil {
+		t.Error("expected error for missing longitude")
+	}
+	if _, err := (Region{Latitude: "north"}).LatFloat(); err == nil {
+		t.Error("expected error for invalid latitude")
+	}
+}
+
+func TestUngeocodedComps(t *testing.T) {
+	var result DeepCompsResult
+	decodeFixture(t, deepCompsPath, &result)
+
+	if !result.Principal.Address.IsGeocoded() {
+		t.Errorf("expected principal to be geocoded: %#v", result.Principal.Address)
+	}
+	comps := result.UngeocodedComps()
+	if len(comps) != 1 || comps[0].Address.Street != "1511 10th Ave W" {
+		t.Fatalf("expected only 1511 10th Ave W but got %#v", comps)
+	}
+	if (Address{Latitude: "47.6", Longitude: ""}).IsGeocoded() {
+		t.Error("expected address without longitude not to be geocoded")
+	}
+}
+
+func TestCompsWithinMiles(t *testing.T) {
+	var result DeepCompsResult
+	decodeFixture(t, deepCompsPath, &result)
+
+	// The first comparable isn't geocoded, and the second is about 0.72 miles
+	// from the principal.
+	for miles, expected := range map[float64][]string{
+		0.5: nil,
+		0.7: nil,
+		0.8: {result.Comparables[1].Zpid},
+		100: {result.Comparables[1].Zpid},
+	} {
+		var zpids []string
+		for _, c := range result.CompsWithinMiles(miles) {
+			zpids = append(zpids, c.Zpid)
+		}
+		if len(zpids) != len(expected) || (len(zpids) > 0 && zpids[0] != expected[0]) {
+			t.Errorf("%v miles: expected %v but got %v", miles, expected, zpids)
+		}
+	}
+
+	result.Principal.Address.Latitude = ""
+	if comps := result.CompsWithinMiles(100); comps != nil {
+		t.Errorf("expected no comps for an ungeocoded principal but got %d", len(comps))
+	}
+
+	// Seattle to Portland is about 145 miles.
+	if d := haversineMiles(47.6062, -122.3321, 45.5152, -122.6784); d < 144 || d > 146 {
+		t.Errorf("expected about 145 miles but got %f", d)
+	}
+}
+
+func TestGeoJSON(t *testing.T) {
+	type feature struct {
+		Type     string
+		Geometry struct {
+			Type        string
+			Coordinates []float64
+		}
+		Properties map[string]interface{}
+	}
+	assertFeature := func(name string, f feature, zpid string) {
+		if f.Type != "Feature" || f.Geometry.Type != "Point" {
+			t.Errorf("%s: expected a Point Feature but got %+v", name, f)
+		}
+		if c := f.Geometry.Coordinates; len(c) != 2 || c[0] != -122.347936 || c[1] != 47.63793 {
+			t.Errorf("%s: expected longitude then latitude but got %v", name, c)
+		}
+		if f.Properties["zpid"] != zpid || f.Properties["zestimate"] != 1219500.0 || f.Properties["currency"] != "USD" || f.Properties["street"] != "2114 Bigelow Ave N" {
+			t.Errorf("%s: unexpected properties %v", name, f.Properties)
+		}
+	}
+
+	var zestimate ZestimateResult
+	decodeFixture(t, zestimatePath, &zestimate)
+	b, err := zestimate.GeoJSONFeature()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f feature
+	if err := json.Unmarshal(b, &f); err != nil {
+		t.Fatal(err)
+	}
+	assertFeature("zestimate", f, zpid)
+
+	zestimate.Address.Longitude = ""
+	if _, err := zestimate.GeoJSONFeature(); err == nil {
+		t.Error("expected error for ungeocoded zestimate")
+	}
+
+	var search SearchResults
+	decodeFixture(t, "GetSearchResultsMulti", &search)
+	search.Results[1].Address.Latitude = ""
+	b, err = search.GeoJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var collection struct {
+		Type     string
+		Features []feature
+	}
+	if err := json.Unmarshal(b, &collection); err != nil {
+		t.Fatal(err)
+	}
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 1 {
+		t.Fatalf("expected a FeatureCollection with the geocoded result but got %s", b)
+	}
+	assertFeature("search result", collection.Features[0], zpid)
+
+	if b, err := (&SearchResults{}).GeoJSON(); err != nil || string(b) != `{"type":"FeatureCollection","features":[]}` {
+		t.Errorf("expected an empty FeatureCollection but got %s, %v", b, err)
+	}
+}
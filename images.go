@@ -0,0 +1,116 @@
+package zillow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// imageEndpoint is the endpoint name RetryIf is given for image downloads.
+const imageEndpoint = "image"
+
+// maxImageBytes is the largest image body downloadBytes accepts.
+const maxImageBytes = 10 << 20
+
+// Download fetches every image URL with at most concurrency requests in
+// flight, using client (or http.DefaultClient if nil). The returned slices are
+// in the same order as Urls; a failed image has a nil body and a non-nil error
+// without affecting the others.
+func (i Images) Download(ctx context.Context, client *http.Client, concurrency int) ([][]byte, []error) {
+	return (&zillow{client: client}).DownloadImages(ctx, i, concurrency)
+}
+
+func (z *zillow) DownloadImages(ctx context.Context, images Images, concurrency int) ([][]byte, []error) {
+	bodies := make([][]byte, len(images.Urls))
+	errs := z.batch(ctx, len(images.Urls), concurrency, func(ctx context.Context, n int) (err error) {
+		bodies[n], _, err = z.downloadBytes(ctx, images.Urls[n])
+		return
+	})
+	return bodies, errs
+}
+
+func (z *zillow) GetChartImage(ctx context.Context, request ChartRequest) ([]byte, string, error) {
+	result, err := z.chart(ctx, request)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := result.Message.Err(); err != nil {
+		return nil, "", err
+	}
+	return z.downloadBytes(ctx, result.Url)
+}
+
+func (z *zillow) GetRegionChartImage(ctx context.Context, request RegionChartRequest) ([]byte, string, error) {
+	result, err := z.regionChart(ctx, request)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := result.Message.Err(); err != nil {
+		return nil, "", err
+	}
+	return z.downloadBytes(ctx, result.Url)
+}
+
+// downloadBytes fetches u, returning the body and its content type. Transient
+// failures, including 5xx responses, are retried according to WithRetry.
+func (z *zillow) downloadBytes(ctx context.Context, u string) ([]byte, string, error) {
+	ctx, cancel, err := z.mergeBaseContext(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cancel()
+	for attempt := 1; ; attempt++ {
+		body, contentType, err := z.downloadOnce(ctx, u)
+		if err == nil || !z.retry.shouldRetry(imageEndpoint, attempt, err) {
+			return body, contentType, err
+		}
+		if !z.retry.wait(ctx, attempt, z.jitter) {
+			return nil, "", err
+		}
+	}
+}
+
+func (z *zillow) downloadOnce(ctx context.Context, u string) ([]byte, string, error) {
+	if u == "" {
+		return nil, "", errors.New("zillow: no image url")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := z.httpClient().Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", &downloadStatusError{url: u, status: resp.Status, code: resp.StatusCode}
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, "", &DecodeError{Err: err}
+	}
+	if len(body) > maxImageBytes {
+		return nil, "", fmt.Errorf("zillow: downloading %s: larger than %d bytes", u, maxImageBytes)
+	}
+	return body, resp.Header.Get("Content-Type"), nil
+}
+
+// downloadStatusError is a non-200 response to an image download.
+type downloadStatusError struct {
+	url    string
+	status string
+	code   int
+}
+
+func (e *downloadStatusError) Error() string {
+	return fmt.Sprintf("zillow: downloading %s: %s", e.url, e.status)
+}
+
+// Retryable reports whether the server failed or asked to be retried later.
+func (e *downloadStatusError) Retryable() bool {
+	return e.code >= 500 || e.code == http.StatusTooManyRequests
+}
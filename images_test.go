@@ -0,0 +1,127 @@
+package zillow
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestImagesDownload(t *testing.T) {
+	var result UpdatedPropertyDetails
+	decodeFixture(t, updatedPropertyDetailsPath, &result)
+
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if r.URL.Path == "/is/image/i0/i0/i64/ISz0l5yjj5pajn.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	// Point the fixture URLs at the test server.
+	images := result.Images
+	for i, u := range images.Urls {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		images.Urls[i] = server.URL + parsed.Path
+	}
+
+	bodies, errs := images.Download(context.Background(), server.Client(), 2)
+	if len(bodies) != 5 || len(errs) != 5 {
+		t.Fatalf("expected 5 results but got %d bodies and %d errors", len(bodies), len(errs))
+	}
+	for i, u := range images.Urls {
+		parsed, _ := url.Parse(u)
+		if i == 2 {
+			if errs[i] == nil || bodies[i] != nil {
+				t.Errorf("expected error for missing image %s", u)
+			}
+			continue
+		}
+		if errs[i] != nil {
+			t.Errorf("%s: %v", u, errs[i])
+		} else if string(bodies[i]) != parsed.Path {
+			t.Errorf("expected body %q but got %q", parsed.Path, bodies[i])
+		}
+	}
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 concurrent downloads but got %d", maxInFlight)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, errs = images.Download(ctx, server.Client(), 2)
+	for i, err := range errs {
+		if err == nil {
+			t.Errorf("expected error for image %d after cancellation", i)
+		}
+	}
+}
+
+func TestGetChartImageRetry(t *testing.T) {
+	fixture, err := ioutil.ReadFile("testdata/" + chartPath + ".xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	image := []byte("\x89PNG\r\n\x1a\n")
+	var imageCalls int
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + chartPath + ".htm":
+			chartURL := regexp.MustCompile(`<url>.*</url>`)
+			w.Write(chartURL.ReplaceAll(fixture, []byte("<url>"+server.URL+"/chart.png</url>")))
+		case "/chart.png":
+			imageCalls++
+			if imageCalls == 1 {
+				http.Error(w, "try again", http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(image)
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	request := ChartRequest{Zpid: zpid, UnitType: unitType, Width: width, Height: height}
+
+	z := NewExt(testZwsId, server.URL)
+	if _, _, err := z.GetChartImage(context.Background(), request); err == nil {
+		t.Fatal("expected error without retry")
+	}
+
+	imageCalls = 0
+	z = NewExt(testZwsId, server.URL, WithRetry(2))
+	body, contentType, err := z.GetChartImage(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(body, image) || contentType != "image/png" {
+		t.Fatalf("expected png image but got %q (%s)", body, contentType)
+	}
+	if imageCalls != 2 {
+		t.Fatalf("expected 2 image calls but got %d", imageCalls)
+	}
+}
@@ -0,0 +1,151 @@
+//go:build integration
+// +build integration
+
+package zillow
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// TestIntegration calls every endpoint on the live API with the key in the
+// ZWSID environment variable. Run with:
+//
+//	ZWSID=... go test -tags integration -run Integration
+//
+// Assertions are loose since live data changes.
+func TestIntegration(t *testing.T) {
+	zwsId := os.Getenv("ZWSID")
+	if zwsId == "" {
+		t.Skip("ZWSID not set")
+	}
+	z := New(zwsId)
+
+	for _, c := range []struct {
+		name string
+		// call returns the result message and a key field which must be set.
+		call func() (Message, string, error)
+	}{
+		{"GetZestimate", func() (Message, string, error) {
+			r, err := z.GetZestimate(ZestimateRequest{Zpid: zpid})
+			if err != nil {
+				return Message{}, "", err
+			}
+			return r.Message, r.Address.Street, nil
+		}},
+		{"GetSearchResults", func() (Message, string, error) {
+			r, err := z.GetSearchResults(SearchRequest{Address: address, CityStateZip: citystatezip})
+			if err != nil || len(r.Results) == 0 {
+				return Message{}, "", err
+			}
+			return r.Message, r.Results[0].Zpid, nil
+		}},
+		{"GetChart", func() (Message, string, error) {
+			r, err := z.GetChart(ChartRequest{Zpid: zpid, UnitType: unitType, Width: width, Height: height})
+			if err != nil {
+				return Message{}, "", err
+			}
+			return r.Message, r.Url, nil
+		}},
+		{"GetComps", func() (Message, string, error) {
+			r, err := z.GetComps(CompsRequest{Zpid: zpid, Count: count})
+			if err != nil {
+				return Message{}, "", err
+			}
+			return r.Message, r.Principal.Zpid, nil
+		}},
+		{"GetRentZestimate", func() (Message, string, error) {
+			r, err := z.GetRentZestimate(context.Background(), zpid)
+			if err != nil {
+				return Message{}, "", err
+			}
+			return Message{}, r.Amount.Currency, nil
+		}},
+		{"GetDeepComps", func() (Message, string, error) {
+			r, err := z.GetDeepComps(CompsRequest{Zpid: zpid, Count: count})
+			if err != nil {
+				return Message{}, "", err
+			}
+			return r.Message, r.Principal.Zpid, nil
+		}},
+		{"GetDeepSearchResults", func() (Message, string, error) {
+			r, err := z.GetDeepSearchResults(SearchRequest{Address: address, CityStateZip: citystatezip})
+			if err != nil || len(r.Results) == 0 {
+				return Message{}, "", err
+			}
+			return r.Message, r.Results[0].Zpid, nil
+		}},
+		{"GetUpdatedPropertyDetails", func() (Message, string, error) {
+			r, err := z.GetUpdatedPropertyDetails(UpdatedPropertyDetailsRequest{Zpid: zpid})
+			if err != nil {
+				return Message{}, "", err
+			}
+			return r.Message, r.Address.Street, nil
+		}},
+		{"GetRegionChildren", func() (Message, string, error) {
+			r, err := z.GetRegionChildren(RegionChildrenRequest{City: regionCity, State: regionState, ChildType: childType})
+			if err != nil || len(r.Regions) == 0 {
+				return Message{}, "", err
+			}
+			return r.Message, r.Regions[0].Id, nil
+		}},
+		{"GetRegionChart", func() (Message, string, error) {
+			r, err := z.GetRegionChart(RegionChartRequest{City: city, State: state, UnitType: unitType, Width: width, Height: height})
+			if err != nil {
+				return Message{}, "", err
+			}
+			return r.Message, r.Url, nil
+		}},
+		{"GetRateSummary", func() (Message, string, error) {
+			r, err := z.GetRateSummary(RateSummaryRequest{})
+			if err != nil || len(r.Today) == 0 {
+				return Message{}, "", err
+			}
+			return r.Message, string(r.Today[0].LoanType), nil
+		}},
+		{"GetMonthlyPayments", func() (Message, string, error) {
+			r, err := z.GetMonthlyPayments(MonthlyPaymentsRequest{Price: price, Down: down, Zip: zip})
+			if err != nil || len(r.Payments) == 0 {
+				return Message{}, "", err
+			}
+			return r.Message, string(r.Payments[0].LoanType), nil
+		}},
+		{"CalculateMonthlyPaymentsAdvanced", func() (Message, string, error) {
+			r, err := z.CalculateMonthlyPaymentsAdvanced(MonthlyPaymentsAdvancedRequest{Price: price, Rate: rate, Schedule: schedule, TermInMonths: termInMonths, Zip: zip})
+			if err != nil {
+				return Message{}, "", err
+			}
+			return r.Message, nonZero(r.TotalMonthlyPayment), nil
+		}},
+		{"CalculateAffordability", func() (Message, string, error) {
+			r, err := z.CalculateAffordability(AffordabilityRequest{AnnualIncome: annualIncome, MonthlyDebts: monthlyDebts, Rate: rate, Schedule: schedule, TermInMonths: termInMonths, Zip: zip})
+			if err != nil {
+				return Message{}, "", err
+			}
+			return r.Message, nonZero(r.AffordabilityAmount), nil
+		}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			message, key, err := c.call()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if message.Code != 0 {
+				t.Fatalf("unexpected message: %s (code %d)", message.Text, message.Code)
+			}
+			if key == "" {
+				t.Fatal("expected a non-empty result")
+			}
+		})
+	}
+}
+
+// nonZero formats i, or returns "" if it is zero.
+func nonZero(i int) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.Itoa(i)
+}
@@ -0,0 +1,268 @@
+package zillow
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keys of the map returned by MonthlyPaymentsAdvanced.Breakdown.
+const (
+	BreakdownPrincipalAndInterest = "principalAndInterest"
+	BreakdownPropertyTaxes        = "propertyTaxes"
+	BreakdownHazardInsurance      = "hazardInsurance"
+	BreakdownPMI                  = "pmi"
+	BreakdownHOADues              = "hoaDues"
+)
+
+// Breakdown returns each component of the monthly payment as a fraction of
+// TotalMonthlyPayment, keyed by the Breakdown* constants. The shares sum to ~1.0.
+// If the total is zero, every share is zero.
+func (m *MonthlyPaymentsAdvanced) Breakdown() map[string]float64 {
+	components := map[string]int{
+		BreakdownPrincipalAndInterest: m.MonthlyPrincipalAndInterest,
+		BreakdownPropertyTaxes:        m.MonthlyPropertyTaxes,
+		BreakdownHazardInsurance:      m.MonthlyHazardInsurance,
+		BreakdownPMI:                  m.MonthlyPMI,
+		BreakdownHOADues:              m.MonthlyHOADues,
+	}
+	shares := make(map[string]float64, len(components))
+	for k, v := range components {
+		if m.TotalMonthlyPayment == 0 {
+			shares[k] = 0
+			continue
+		}
+		shares[k] = float64(v) / float64(m.TotalMonthlyPayment)
+	}
+	return shares
+}
+
+// TCO is the total cost of owning a home over a number of years.
+type TCO struct {
+	Years int
+	// DownPayment is the home price less the loan amount.
+	DownPayment int
+	// TotalPaid is DownPayment plus every TotalMonthlyPayment over Years.
+	TotalPaid int
+	// EquityBuilt is the principal repaid over Years, per the amortization
+	// schedule. It excludes DownPayment and appreciation.
+	EquityBuilt int
+	// HomeValue is the home price after Years of appreciation.
+	HomeValue int
+}
+
+// CostOfOwnership returns the TCO of buying at homePrice and holding for years,
+// with the home appreciating by annualAppreciation a year, e.g. 0.03 for 3%.
+// The monthly payment is assumed constant. The loan amount is the beginning
+// balance of the amortization schedule, and principal is only counted for the
+// periods the schedule covers. It makes no API call.
+func (m *MonthlyPaymentsAdvanced) CostOfOwnership(years int, annualAppreciation float64, homePrice int) TCO {
+	tco := TCO{Years: years}
+	payments := m.AmortizationSchedule.Payments
+	if len(payments) > 0 && homePrice > payments[0].BeginningBalance {
+		tco.DownPayment = homePrice - payments[0].BeginningBalance
+	}
+	tco.TotalPaid = tco.DownPayment + m.TotalMonthlyPayment*12*years
+	periods := years
+	if m.AmortizationSchedule.Frequency == "monthly" {
+		periods *= 12
+	}
+	for i := 0; i < periods && i < len(payments); i++ {
+		tco.EquityBuilt += payments[i].Principal
+	}
+	tco.HomeValue = int(math.Round(float64(homePrice) * math.Pow(1+annualAppreciation, float64(years))))
+	return tco
+}
+
+// Percent is a percentage as a whole number, e.g. 36 for 36%, not 0.36.
+type Percent float32
+
+// Valid reports whether p is within [0, 100].
+func (p Percent) Valid() bool {
+	return p >= 0 && p <= 100
+}
+
+// ClosingCostOptions are the closing costs of a purchase, each as a
+// percentage of the price.
+type ClosingCostOptions struct {
+	Title       Percent
+	Escrow      Percent
+	Origination Percent
+}
+
+// DefaultClosingCostOptions are typical closing costs: 0.5% each for title
+// insurance and escrow, and a 1% origination fee.
+var DefaultClosingCostOptions = ClosingCostOptions{Title: 0.5, Escrow: 0.5, Origination: 1}
+
+// EstimateClosingCosts returns the closing costs of buying at price, rounded
+// to the nearest dollar. It makes no API call.
+func EstimateClosingCosts(price int, opts ClosingCostOptions) int {
+	percent := float64(opts.Title) + float64(opts.Escrow) + float64(opts.Origination)
+	return int(math.Round(float64(price) * percent / 100))
+}
+
+// EstimateClosingCosts sets EstimatedClosingCosts to the closing costs of the
+// requested price, and returns them.
+func (m *MonthlyPayments) EstimateClosingCosts(opts ClosingCostOptions) int {
+	m.EstimatedClosingCosts = EstimateClosingCosts(m.Request.Price, opts)
+	return m.EstimatedClosingCosts
+}
+
+// LoanType identifies the loan a Rate or Payment applies to.
+type LoanType string
+
+const (
+	ThirtyYearFixed  LoanType = "thirtyYearFixed"
+	FifteenYearFixed LoanType = "fifteenYearFixed"
+	FiveOneARM       LoanType = "fiveOneARM"
+	// The following are only returned for some accounts and markets. Rates
+	// with other loan types decode too, with LoanType set as reported.
+	ThreeOneARM        LoanType = "threeOneARM"
+	SevenOneARM        LoanType = "sevenOneARM"
+	ThirtyYearFixedFHA LoanType = "thirtyYearFixedFHA"
+)
+
+// AsOfTime parses AsOf.
+func (r *RateSummary) AsOfTime() (time.Time, error) {
+	return time.Parse(time.RFC3339, r.AsOf)
+}
+
+// RatePoint is a single rate in a series. When is "lastWeek" or "today".
+type RatePoint struct {
+	When  string
+	Value float64
+}
+
+// Series returns the rates for loanType in chronological order: last week,
+// then today. It fails if loanType isn't present in both.
+func (r *RateSummary) Series(loanType LoanType) ([]RatePoint, error) {
+	lastWeek, ok := findRate(r.LastWeek, loanType)
+	if !ok {
+		return nil, fmt.Errorf("zillow: no %s rate for last week", loanType)
+	}
+	today, ok := findRate(r.Today, loanType)
+	if !ok {
+		return nil, fmt.Errorf("zillow: no %s rate for today", loanType)
+	}
+	return []RatePoint{
+		{When: "lastWeek", Value: lastWeek},
+		{When: "today", Value: today},
+	}, nil
+}
+
+// WeekOverWeekChange returns today's rate minus last week's for each loan
+// type present in both, e.g. -0.11 for a drop from 6.02 to 5.91.
+func (r *RateSummary) WeekOverWeekChange() map[LoanType]float64 {
+	changes := make(map[LoanType]float64)
+	for _, today := range r.Today {
+		if lastWeek, ok := findRate(r.LastWeek, today.LoanType); ok {
+			changes[today.LoanType] = today.Value - lastWeek
+		}
+	}
+	return changes
+}
+
+func findRate(rates []Rate, loanType LoanType) (float64, bool) {
+	for _, r := range rates {
+		if r.LoanType == loanType {
+			return r.Value, true
+		}
+	}
+	return 0, false
+}
+
+// RateAffordability is the affordability at one of the rates of a
+// CalculateAffordabilitySensitivity call.
+type RateAffordability struct {
+	Rate float32
+	// Affordability is nil if the call for Rate failed.
+	Affordability *Affordability
+}
+
+// affordabilitySensitivityConcurrency is the most affordability calls
+// CalculateAffordabilitySensitivity has in flight.
+const affordabilitySensitivityConcurrency = 4
+
+func (z *zillow) CalculateAffordabilitySensitivity(ctx context.Context, base AffordabilityRequest, deltas []float32) ([]RateAffordability, error) {
+	seen := make(map[float32]bool, len(deltas))
+	for _, d := range deltas {
+		if seen[d] {
+			return nil, fmt.Errorf("zillow: repeated rate delta %s", formatRate(d))
+		}
+		seen[d] = true
+	}
+	results := make([]RateAffordability, len(deltas))
+	for i, d := range deltas {
+		results[i].Rate = base.Rate + d
+	}
+	errs := z.batch(ctx, len(deltas), affordabilitySensitivityConcurrency, func(ctx context.Context, i int) (err error) {
+		request := base
+		request.Rate = results[i].Rate
+		results[i].Affordability, err = z.affordability(ctx, request)
+		return
+	})
+	var failed []string
+	var firstErr error
+	for i, err := range errs {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed = append(failed, formatRate(results[i].Rate))
+		}
+	}
+	if firstErr != nil {
+		return results, fmt.Errorf("zillow: affordability failed for rates %s: %w", strings.Join(failed, ", "), firstErr)
+	}
+	return results, nil
+}
+
+func formatRate(rate float32) string {
+	return strconv.FormatFloat(float64(rate), 'f', -1, 32)
+}
+
+// amortizationHeader is the header row written by the WriteCSV methods.
+var amortizationHeader = []string{"period", "beginning", "payment", "principal", "interest", "ending"}
+
+// WriteCSV writes the schedule to w as CSV, with a header row followed by a
+// row per payment. Payments are numbered from 1.
+func (s AmortizationSchedule) WriteCSV(w io.Writer) error {
+	rows := make([][6]int, len(s.Payments))
+	for i, p := range s.Payments {
+		rows[i] = [6]int{i + 1, p.BeginningBalance, p.Amount, p.Principal, p.Interest, p.EndingBalance}
+	}
+	return writeAmortizationCSV(w, rows)
+}
+
+// WriteCSV is like AmortizationSchedule.WriteCSV, but uses each payment's
+// Period.
+func (s AffordabilityAmortizationSchedule) WriteCSV(w io.Writer) error {
+	rows := make([][6]int, len(s.Payments))
+	for i, p := range s.Payments {
+		rows[i] = [6]int{p.Period, p.BeginningBalance, p.Payment, p.Principal, p.Interest, p.EndingBalance}
+	}
+	return writeAmortizationCSV(w, rows)
+}
+
+func writeAmortizationCSV(w io.Writer, rows [][6]int) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(amortizationHeader); err != nil {
+		return err
+	}
+	record := make([]string, len(amortizationHeader))
+	for _, row := range rows {
+		for i, v := range row {
+			record[i] = strconv.Itoa(v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,299 @@
+package zillow
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMonthlyPaymentsAdvancedBreakdown(t *testing.T) {
+	var result MonthlyPaymentsAdvanced
+	decodeFixture(t, monthlyPaymentsAdvancedPath, &result)
+
+	shares := result.Breakdown()
+	if len(shares) != 5 {
+		t.Fatalf("expected 5 shares but got %d: %v", len(shares), shares)
+	}
+	var sum float64
+	for _, s := range shares {
+		sum += s
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Fatalf("expected shares to sum to 1 but got %f", sum)
+	}
+	if expected, actual := 3200.0/5038, shares[BreakdownHOADues]; actual != expected {
+		t.Fatalf("expected hoa share %f but got %f", expected, actual)
+	}
+
+	for k, s := range (&MonthlyPaymentsAdvanced{}).Breakdown() {
+		if s != 0 {
+			t.Fatalf("expected zero %s share for zero total but got %f", k, s)
+		}
+	}
+}
+
+func TestCostOfOwnership(t *testing.T) {
+	var result MonthlyPaymentsAdvanced
+	decodeFixture(t, monthlyPaymentsAdvancedPath, &result)
+
+	expected := TCO{
+		Years:       3,
+		DownPayment: 60000,
+		TotalPaid:   60000 + 5038*36,
+		EquityBuilt: 2947 + 3129 + 3322,
+		// 300000 * 1.03^3
+		HomeValue: 327818,
+	}
+	if actual := result.CostOfOwnership(3, 0.03, 300000); actual != expected {
+		t.Errorf("expected %+v but got %+v", expected, actual)
+	}
+
+	// The schedule only covers 3 years.
+	if actual := result.CostOfOwnership(5, 0, 300000); actual.EquityBuilt != expected.EquityBuilt || actual.HomeValue != 300000 {
+		t.Errorf("expected equity %d and no appreciation but got %+v", expected.EquityBuilt, actual)
+	}
+}
+
+func TestEstimateClosingCosts(t *testing.T) {
+	for _, c := range []struct {
+		price    int
+		opts     ClosingCostOptions
+		expected int
+	}{
+		{300000, DefaultClosingCostOptions, 6000},
+		{300000, ClosingCostOptions{Title: 0.75, Escrow: 0.25, Origination: 0.5}, 4500},
+		{333333, ClosingCostOptions{Origination: 1}, 3333},
+		{300000, ClosingCostOptions{}, 0},
+	} {
+		if actual := EstimateClosingCosts(c.price, c.opts); actual != c.expected {
+			t.Errorf("%d with %+v: expected %d but got %d", c.price, c.opts, c.expected, actual)
+		}
+	}
+
+	var result MonthlyPayments
+	decodeFixture(t, monthlyPaymentsPath, &result)
+	if actual := result.EstimateClosingCosts(DefaultClosingCostOptions); actual != 6000 || result.EstimatedClosingCosts != 6000 {
+		t.Errorf("expected 6000 but got %d and field %d", actual, result.EstimatedClosingCosts)
+	}
+}
+
+func TestRateSummarySeries(t *testing.T) {
+	var result RateSummary
+	decodeFixture(t, rateSummaryPath, &result)
+
+	series, err := result.Series(FifteenYearFixed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []RatePoint{{When: "lastWeek", Value: 5.94}, {When: "today", Value: 5.68}}
+	if !reflect.DeepEqual(series, expected) {
+		t.Fatalf("expected %v but got %v", expected, series)
+	}
+
+	if _, err := result.Series("sevenOneARM"); err == nil {
+		t.Fatal("expected error for missing loan type")
+	}
+	result.Today = result.Today[:1]
+	if _, err := result.Series(FiveOneARM); err == nil {
+		t.Fatal("expected error for loan type missing today")
+	}
+}
+
+func TestRateSummaryWeekOverWeekChange(t *testing.T) {
+	var result RateSummary
+	decodeFixture(t, rateSummaryPath, &result)
+	// Drop last week's 5/1 ARM, so it's skipped.
+	result.LastWeek = result.LastWeek[:2]
+
+	changes := result.WeekOverWeekChange()
+	expected := map[LoanType]float64{
+		ThirtyYearFixed:  5.91 - 6.02,
+		FifteenYearFixed: 5.68 - 5.94,
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, changes)
+	}
+	for loanType, e := range expected {
+		if actual, ok := changes[loanType]; !ok || math.Abs(actual-e) > 1e-9 {
+			t.Errorf("%s: expected %f but got %f", loanType, e, actual)
+		}
+	}
+}
+
+func TestRateSummaryExtended(t *testing.T) {
+	var result RateSummary
+	decodeFixture(t, "GetRateSummaryExtended", &result)
+
+	expected := []Rate{
+		{LoanType: ThirtyYearFixed, Count: 1252, Value: 5.91},
+		{LoanType: FifteenYearFixed, Count: 839, Value: 5.68},
+		{LoanType: FiveOneARM, Count: 685, Value: 5.49},
+		{LoanType: SevenOneARM, Count: 412, Value: 5.55},
+		{LoanType: ThirtyYearFixedFHA, Count: 301, Value: 5.62},
+		{LoanType: "tenYearFixed", Count: 57, Value: 5.31},
+	}
+	if !reflect.DeepEqual(result.Today, expected) {
+		t.Fatalf("expected:\n %s\n\n but got:\n %s", prettyJSON(t, expected), prettyJSON(t, result.Today))
+	}
+	if len(result.LastWeek) != 6 {
+		t.Fatalf("expected 6 rates last week but got %d", len(result.LastWeek))
+	}
+
+	series, err := result.Series(SevenOneARM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []RatePoint{{When: "lastWeek", Value: 5.80}, {When: "today", Value: 5.55}}; !reflect.DeepEqual(series, expected) {
+		t.Fatalf("expected %v but got %v", expected, series)
+	}
+}
+
+func TestPercentValid(t *testing.T) {
+	for p, expected := range map[Percent]bool{
+		0:     true,
+		0.36:  true,
+		36:    true,
+		100:   true,
+		-1:    false,
+		100.5: false,
+		3600:  false,
+	} {
+		if actual := p.Valid(); actual != expected {
+			t.Errorf("%v: expected %t but got %t", p, expected, actual)
+		}
+	}
+}
+
+func TestRateSummaryAsOf(t *testing.T) {
+	server, zillow := testFixtures(t, rateSummaryPath, func(url.Values) {})
+	defer server.Close()
+
+	before := time.Now().Truncate(time.Second)
+	result, err := zillow.GetRateSummary(RateSummaryRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asOf, err := result.AsOfTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asOf.Before(before) || asOf.After(time.Now()) {
+		t.Fatalf("expected AsOf to be the fetch time but got %s", result.AsOf)
+	}
+
+	if _, err := (&RateSummary{}).AsOfTime(); err == nil {
+		t.Fatal("expected error parsing empty AsOf")
+	}
+}
+
+func TestCalculateAffordabilitySensitivity(t *testing.T) {
+	var mu sync.Mutex
+	var rates []string
+	var inFlight, maxInFlight int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		rates = append(rates, r.URL.Query().Get(rateParam))
+		if inFlight++; inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		time.Sleep(10 * time.Millisecond)
+		http.ServeFile(w, r, "testdata/"+affordabilityPath+".xml")
+	}))
+	defer server.Close()
+	zillow := &zillow{zwsId: testZwsId, url: server.URL}
+
+	base := AffordabilityRequest{AnnualIncome: annualIncome, MonthlyDebts: monthlyDebts, Rate: rate, Schedule: schedule, TermInMonths: termInMonths, Zip: zip}
+	deltas := []float32{0.5, -0.5, 0.25, -0.25, 1, -1}
+	results, err := zillow.CalculateAffordabilitySensitivity(context.Background(), base, deltas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(rates)
+	if expected := []string{"5", "5.5", "5.75", "6.25", "6.5", "7"}; !reflect.DeepEqual(rates, expected) {
+		t.Fatalf("expected rates %v but got %v", expected, rates)
+	}
+	if maxInFlight > affordabilitySensitivityConcurrency {
+		t.Errorf("expected at most %d calls in flight but got %d", affordabilitySensitivityConcurrency, maxInFlight)
+	}
+	if len(results) != len(deltas) {
+		t.Fatalf("expected %d results but got %v", len(deltas), results)
+	}
+	for i, r := range results {
+		if r.Rate != rate+deltas[i] || r.Affordability == nil || r.Affordability.AffordabilityAmount == 0 {
+			t.Errorf("%d: expected affordability at rate %v but got %+v", i, rate+deltas[i], r)
+		}
+	}
+
+	if _, err := zillow.CalculateAffordabilitySensitivity(context.Background(), base, []float32{0.5, -0.5, 0.5}); err == nil {
+		t.Error("expected error for repeated delta")
+	}
+}
+
+func TestCalculateAffordabilitySensitivityIsolatesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get(rateParam) == "6.5" {
+			io.WriteString(w, "<html><body>Service error</body></html>")
+			return
+		}
+		http.ServeFile(w, r, "testdata/"+affordabilityPath+".xml")
+	}))
+	defer server.Close()
+	zillow := &zillow{zwsId: testZwsId, url: server.URL}
+
+	base := AffordabilityRequest{AnnualIncome: annualIncome, MonthlyDebts: monthlyDebts, Rate: rate, Schedule: schedule, TermInMonths: termInMonths}
+	results, err := zillow.CalculateAffordabilitySensitivity(context.Background(), base, []float32{-0.5, 0.5})
+	if !errors.Is(err, ErrNotXML) || !strings.Contains(err.Error(), "6.5") {
+		t.Fatalf("expected error for rate 6.5 but got %v", err)
+	}
+	if len(results) != 2 || results[0].Affordability == nil || results[1].Rate != 6.5 || results[1].Affordability != nil {
+		t.Fatalf("expected only the 5.5 result but got %+v", results)
+	}
+}
+
+func TestAmortizationScheduleWriteCSV(t *testing.T) {
+	var advanced MonthlyPaymentsAdvanced
+	decodeFixture(t, monthlyPaymentsAdvancedPath, &advanced)
+	var b strings.Builder
+	if err := advanced.AmortizationSchedule.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	expected := `period,beginning,payment,principal,interest,ending
+1,240000,17267,2947,14320,237053
+2,237053,17267,3129,14138,233924
+3,233924,17267,3322,13945,230602
+`
+	if actual := b.String(); actual != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, actual)
+	}
+
+	var affordability Affordability
+	decodeFixture(t, affordabilityPath, &affordability)
+	b.Reset()
+	if err := affordability.AmortizationSchedule.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	expected = `period,beginning,payment,principal,interest,ending
+1,152269,11554,1701,9853,150569
+2,150569,11554,1815,9740,148754
+3,148754,11554,1936,9618,146818
+`
+	if actual := b.String(); actual != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,41 @@
+package zillow
+
+import "time"
+
+// CallStats describes one attempt of a call to an endpoint.
+type CallStats struct {
+	// Endpoint is the API name, e.g. "GetZestimate".
+	Endpoint string
+	// Attempt is the number of the attempt, starting from 1.
+	Attempt int
+	// BodyBytes is the size of the response body, or 0 if none was read.
+	BodyBytes int
+	// DecodeDuration is how long decoding the body took, excluding the time
+	// spent on the network, except for GetDeepSearchResultsStream, which
+	// decodes the body as it arrives.
+	DecodeDuration time.Duration
+	// Err is the error the attempt failed with, if any.
+	Err error
+	// StoreErr is the error saving the response for WithResponseStore, if
+	// any. It doesn't fail the attempt.
+	StoreErr error
+}
+
+// Observer is called after each attempt of a call.
+type Observer func(CallStats)
+
+// WithObserver makes the client report each attempt of a call to observer,
+// e.g. to record response sizes and decode times. observer may be called
+// concurrently by batch methods.
+func WithObserver(observer Observer) Option {
+	return func(z *zillow) {
+		z.observer = observer
+	}
+}
+
+// observe reports stats to the observer, if any.
+func (z *zillow) observe(stats CallStats) {
+	if z.observer != nil {
+		z.observer(stats)
+	}
+}
@@ -0,0 +1,49 @@
+package zillow
+
+import (
+	"io/ioutil"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithObserver(t *testing.T) {
+	var mu sync.Mutex
+	var calls []CallStats
+	observer := WithObserver(func(stats CallStats) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, stats)
+	})
+
+	server, client := testFixtures(t, zestimatePath, func(url.Values) {}, observer)
+	defer server.Close()
+	if _, err := client.GetZestimate(ZestimateRequest{Zpid: zpid}); err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := ioutil.ReadFile("testdata/" + zestimatePath + ".xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 call but got %v", calls)
+	}
+	if c := calls[0]; c.Endpoint != zestimatePath || c.Attempt != 1 || c.BodyBytes != len(fixture) || c.DecodeDuration <= 0 || c.Err != nil {
+		t.Errorf("expected a successful %d byte attempt with a decode time but got %+v", len(fixture), c)
+	}
+
+	// Each attempt is reported.
+	calls = nil
+	truncating, _ := truncatingServer(t, zestimatePath, 1)
+	defer truncating.Close()
+	z := &zillow{zwsId: testZwsId, url: truncating.URL}
+	observer(z)
+	WithRetry(2, RetryBackoff(func(int) time.Duration { return 0 }))(z)
+	if _, err := z.GetZestimate(ZestimateRequest{Zpid: zpid}); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0].Err == nil || calls[1].Err != nil || calls[1].Attempt != 2 {
+		t.Errorf("expected a failed attempt then a successful one but got %+v", calls)
+	}
+}
@@ -0,0 +1,117 @@
+package zillow
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Option configures a Zillow client created by New or NewExt.
+type Option func(*zillow)
+
+// WithDefaultRentZestimate requests rent data by default from GetZestimate,
+// GetSearchResults, GetComps, GetDeepComps and GetDeepSearchResults. A request
+// with Rentzestimate set always asks for rent data, and one with
+// NoRentzestimate set opts out of a true default.
+func WithDefaultRentZestimate(rentzestimate bool) Option {
+	return func(z *zillow) {
+		z.defaultRentZestimate = rentzestimate
+	}
+}
+
+// WithRequestEchoCheck makes calls compare the zpid or address Zillow echoes
+// back in the result's Request against what was sent, and fail with a
+// *RequestMismatchError (matching ErrRequestMismatch) when they differ, e.g.
+// because Zillow normalized an address.
+func WithRequestEchoCheck() Option {
+	return func(z *zillow) {
+		z.echoCheck = true
+	}
+}
+
+// WithMinComps makes GetComps and GetDeepComps fail with an
+// *InsufficientCompsError (matching ErrInsufficientComps) when a successful
+// response has fewer than n comparables.
+func WithMinComps(n int) Option {
+	return func(z *zillow) {
+		z.minComps = n
+	}
+}
+
+// WithStrictCompsCount makes GetComps and GetDeepComps fail with a
+// *CountTooHighError (matching ErrCountTooHigh) for a Count over Zillow's
+// maximum of 25, without making a request. By default such a Count is lowered
+// to 25.
+func WithStrictCompsCount() Option {
+	return func(z *zillow) {
+		z.strictCompsCount = true
+	}
+}
+
+// WithStrictSingleResult makes GetSearchResults and GetDeepSearchResults fail
+// with an *AmbiguousAddressError (matching ErrAmbiguousAddress) when more than
+// one result is returned, instead of leaving the caller to pick one.
+func WithStrictSingleResult() Option {
+	return func(z *zillow) {
+		z.strictSingleResult = true
+	}
+}
+
+// WithMaxConcurrency caps the number of calls in flight across all batch
+// methods, such as GetZestimates, at n, in addition to the concurrency each
+// batch call is given. An n of 0 or less means no limit.
+func WithMaxConcurrency(n int) Option {
+	return func(z *zillow) {
+		if n <= 0 {
+			z.sem = nil
+			return
+		}
+		z.sem = make(chan struct{}, n)
+	}
+}
+
+// WithPathSuffix replaces the ".htm" suffix appended to each endpoint path,
+// e.g. with "" to call a mock server that serves /GetZestimate.
+func WithPathSuffix(suffix string) Option {
+	return func(z *zillow) {
+		z.pathSuffix = &suffix
+	}
+}
+
+// WithAcceptLanguage sends language as the Accept-Language header on every
+// call. Zillow doesn't document which endpoints honor it; the data is
+// US-centric, so at most message text and formatting are expected to vary.
+func WithAcceptLanguage(language string) Option {
+	return func(z *zillow) {
+		z.acceptLanguage = language
+	}
+}
+
+// WithBaseContext makes every call also use ctx, in addition to its own
+// context, so cancelling ctx, e.g. for a graceful shutdown, aborts calls in
+// flight and fails later ones immediately with ctx's error.
+func WithBaseContext(ctx context.Context) Option {
+	return func(z *zillow) {
+		z.baseCtx = ctx
+	}
+}
+
+// WithClock sets the source of the current time, which is used for
+// timestamps such as RateSummary.AsOf. The default is time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(z *zillow) {
+		z.clock = now
+	}
+}
+
+// WithTLSConfig makes calls over a clone of http.DefaultTransport using
+// config, e.g. to trust a proxy's custom CA, instead of over
+// http.DefaultClient. config is cloned, so later changes to it have no effect.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(z *zillow) {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = config.Clone()
+		z.client = &http.Client{Transport: t}
+	}
+}
@@ -0,0 +1,222 @@
+package zillow
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithDefaultRentZestimate(t *testing.T) {
+	for _, c := range []struct {
+		path string
+		call func(z Zillow, rent, noRent bool) error
+	}{
+		{zestimatePath, func(z Zillow, rent, noRent bool) error {
+			_, err := z.GetZestimate(ZestimateRequest{Zpid: zpid, Rentzestimate: rent, NoRentzestimate: noRent})
+			return err
+		}},
+		{searchResultsPath, func(z Zillow, rent, noRent bool) error {
+			_, err := z.GetSearchResults(SearchRequest{Address: address, CityStateZip: citystatezip, Rentzestimate: rent, NoRentzestimate: noRent})
+			return err
+		}},
+		{compsPath, func(z Zillow, rent, noRent bool) error {
+			_, err := z.GetComps(CompsRequest{Zpid: zpid, Count: count, Rentzestimate: rent, NoRentzestimate: noRent})
+			return err
+		}},
+		{deepCompsPath, func(z Zillow, rent, noRent bool) error {
+			_, err := z.GetDeepComps(CompsRequest{Zpid: zpid, Count: count, Rentzestimate: rent, NoRentzestimate: noRent})
+			return err
+		}},
+		{deepSearchPath, func(z Zillow, rent, noRent bool) error {
+			_, err := z.GetDeepSearchResults(SearchRequest{Address: address, CityStateZip: citystatezip, Rentzestimate: rent, NoRentzestimate: noRent})
+			return err
+		}},
+	} {
+		for _, d := range []struct {
+			def, rent, noRent bool
+			expected          string
+		}{
+			{false, false, false, "false"},
+			{true, false, false, "true"},
+			{false, true, false, "true"},
+			{true, true, false, "true"},
+			{true, false, true, "false"},
+			{false, false, true, "false"},
+			{true, true, true, "true"},
+		} {
+			server, zillow := testFixtures(t, c.path, func(values url.Values) {
+				assertOnlyParam(t, values, rentzestimateParam, d.expected)
+			}, WithDefaultRentZestimate(d.def))
+			if err := c.call(zillow, d.rent, d.noRent); err != nil {
+				t.Error(err)
+			}
+			server.Close()
+		}
+	}
+}
+
+func TestWithRequestEchoCheck(t *testing.T) {
+	request := SearchRequest{Address: address, CityStateZip: citystatezip}
+
+	server, zillow := testFixture(t, searchResultsPath, "GetSearchResultsNormalized", func(url.Values) {})
+	defer server.Close()
+	if _, err := zillow.GetSearchResults(request); err != nil {
+		t.Fatalf("expected no check by default but got %v", err)
+	}
+
+	server, zillow = testFixture(t, searchResultsPath, "GetSearchResultsNormalized", func(url.Values) {}, WithRequestEchoCheck())
+	defer server.Close()
+	_, err := zillow.GetSearchResults(request)
+	if !errors.Is(err, ErrRequestMismatch) {
+		t.Fatalf("expected ErrRequestMismatch but got %v", err)
+	}
+	var mismatch *RequestMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected RequestMismatchError but got %T", err)
+	}
+	expected := RequestMismatchError{Param: addressParam, Sent: address, Echoed: "2114 Bigelow Ave N"}
+	if *mismatch != expected {
+		t.Fatalf("expected %+v but got %+v", expected, *mismatch)
+	}
+
+	server, zillow = testFixtures(t, searchResultsPath, func(url.Values) {}, WithRequestEchoCheck())
+	defer server.Close()
+	if _, err := zillow.GetSearchResults(request); err != nil {
+		t.Fatalf("expected matching echo to pass but got %v", err)
+	}
+}
+
+func TestWithPathSuffix(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+zestimatePath {
+			t.Errorf("expected path /%s but got %s", zestimatePath, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, "testdata/"+zestimatePath+".xml")
+	}))
+	defer server.Close()
+
+	z := NewExt(testZwsId, server.URL, WithPathSuffix(""))
+	result, err := z.GetZestimate(ZestimateRequest{Zpid: zpid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Zpid != zpid {
+		t.Fatalf("expected zpid %s but got %#v", zpid, result)
+	}
+}
+
+func TestWithAcceptLanguage(t *testing.T) {
+	for _, language := range []string{"", "es-US"} {
+		var header []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header["Accept-Language"]
+			http.ServeFile(w, r, "testdata/"+zestimatePath+".xml")
+		}))
+		var opts []Option
+		if language != "" {
+			opts = append(opts, WithAcceptLanguage(language))
+		}
+		_, err := NewExt(testZwsId, server.URL, opts...).GetZestimate(ZestimateRequest{Zpid: zpid})
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if language == "" {
+			if header != nil {
+				t.Errorf("expected no Accept-Language header but got %q", header)
+			}
+		} else if len(header) != 1 || header[0] != language {
+			t.Errorf("expected Accept-Language %q but got %q", language, header)
+		}
+	}
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/"+zestimatePath+".xml")
+	}))
+	defer server.Close()
+
+	if _, err := NewExt(testZwsId, server.URL).GetZestimate(ZestimateRequest{Zpid: zpid}); err == nil {
+		t.Fatal("expected an untrusted certificate to fail by default")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	config := &tls.Config{RootCAs: pool}
+	z := NewExt(testZwsId, server.URL, WithTLSConfig(config))
+	config.RootCAs = nil
+	result, err := z.GetZestimate(ZestimateRequest{Zpid: zpid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Zpid != zpid {
+		t.Fatalf("expected zpid %s but got %#v", zpid, result)
+	}
+}
+
+func TestWithBaseContext(t *testing.T) {
+	var calls int32
+	started := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		// Hang until the client gives up.
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	base, cancel := context.WithCancel(context.Background())
+	z := NewExt(testZwsId, server.URL, WithBaseContext(base))
+
+	errs := make(chan error)
+	go func() {
+		_, err := z.GetZestimate(ZestimateRequest{Zpid: zpid})
+		errs <- err
+	}()
+	<-started
+	cancel()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected in-flight call to be canceled but got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("in-flight call wasn't aborted")
+	}
+
+	if _, err := z.GetZestimate(ZestimateRequest{Zpid: zpid}); err != context.Canceled {
+		t.Errorf("expected later call to fail with the base context's error but got %v", err)
+	}
+	if err := z.GetDeepSearchResultsStream(context.Background(), SearchRequest{Address: address, CityStateZip: citystatezip}, func(DeepSearchResult) error { return nil }); err != context.Canceled {
+		t.Errorf("expected later stream to fail with the base context's error but got %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected only the first call to reach the server but got %d", n)
+	}
+}
+
+func TestWithBaseContextLive(t *testing.T) {
+	server, client := testFixture(t, zestimatePath, zestimatePath, func(url.Values) {}, WithBaseContext(context.Background()))
+	defer server.Close()
+	if _, err := client.GetZestimate(ZestimateRequest{Zpid: zpid}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A per-call context is still honored.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.GetRentZestimate(ctx, zpid); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled call but got %v", err)
+	}
+}
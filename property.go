@@ -0,0 +1,314 @@
+package zillow
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// PropertyType is a normalized useCode.
+type PropertyType string
+
+const (
+	UnknownPropertyType PropertyType = ""
+	SingleFamily        PropertyType = "SingleFamily"
+	Duplex              PropertyType = "Duplex"
+	Triplex             PropertyType = "Triplex"
+	Quadruplex          PropertyType = "Quadruplex"
+	Condominium         PropertyType = "Condominium"
+	Cooperative         PropertyType = "Cooperative"
+	Townhouse           PropertyType = "Townhouse"
+	Multifamily         PropertyType = "Multifamily"
+	Apartment           PropertyType = "Apartment"
+	Mobile              PropertyType = "Mobile"
+	VacantLand          PropertyType = "VacantLand"
+	Timeshare           PropertyType = "Timeshare"
+	Miscellaneous       PropertyType = "Miscellaneous"
+)
+
+// propertyTypes maps a folded useCode (see foldUseCode) to its PropertyType.
+var propertyTypes = map[string]PropertyType{
+	"singlefamily":          SingleFamily,
+	"duplex":                Duplex,
+	"triplex":               Triplex,
+	"quadruplex":            Quadruplex,
+	"condominium":           Condominium,
+	"condo":                 Condominium,
+	"cooperative":           Cooperative,
+	"coop":                  Cooperative,
+	"townhouse":             Townhouse,
+	"multifamily":           Multifamily,
+	"multifamily2to4":       Multifamily,
+	"multifamily5plus":      Multifamily,
+	"apartment":             Apartment,
+	"mobile":                Mobile,
+	"mobilehome":            Mobile,
+	"vacantresidentialland": VacantLand,
+	"vacantland":            VacantLand,
+	"timeshare":             Timeshare,
+	"miscellaneous":         Miscellaneous,
+}
+
+// ParsePropertyType maps a useCode to a PropertyType, ignoring case, spaces,
+// hyphens and underscores, so "SingleFamily" and "Single family" are both
+// SingleFamily. Unrecognized codes are UnknownPropertyType.
+func ParsePropertyType(useCode string) PropertyType {
+	return propertyTypes[foldUseCode(useCode)]
+}
+
+func foldUseCode(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_':
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, s)
+}
+
+// PropertyType returns the parsed UseCode.
+func (r *DeepSearchResult) PropertyType() PropertyType {
+	return ParsePropertyType(r.UseCode)
+}
+
+// PricePerSqFt returns the Zestimate per finished square foot, or false if
+// FinishedSqFt is zero.
+func (r *DeepSearchResult) PricePerSqFt() (float64, bool) {
+	return perSqFt(r.Zestimate.Amount.Value, r.FinishedSqFt)
+}
+
+// LastSoldPricePerSqFt returns the last sold price per finished square foot, or
+// false if FinishedSqFt is zero.
+func (r *DeepSearchResult) LastSoldPricePerSqFt() (float64, bool) {
+	return perSqFt(r.LastSoldPrice.Value, r.FinishedSqFt)
+}
+
+func perSqFt(price, sqft int) (float64, bool) {
+	if sqft == 0 {
+		return 0, false
+	}
+	return float64(price) / float64(sqft), true
+}
+
+// AnnualizedAppreciation returns the compound annual growth rate from
+// LastSoldPrice on LastSoldDate to the Zestimate amount on its LastUpdated
+// date, e.g. 0.05 for 5% a year.
+func (r *DeepSearchResult) AnnualizedAppreciation() (float64, error) {
+	return annualizedAppreciation(r.LastSoldPrice.Value, r.LastSoldDate, r.Zestimate)
+}
+
+// AnnualizedAppreciation is like DeepSearchResult.AnnualizedAppreciation.
+func (c *DeepComp) AnnualizedAppreciation() (float64, error) {
+	return annualizedAppreciation(c.LastSoldPrice.Value, c.LastSoldDate, c.Zestimate)
+}
+
+func annualizedAppreciation(soldPrice int, soldDate string, z Zestimate) (float64, error) {
+	if soldPrice <= 0 {
+		return 0, errors.New("zillow: no last sold price")
+	} else if z.Amount.Value <= 0 {
+		return 0, errors.New("zillow: no zestimate amount")
+	}
+	sold, err := parseDate(soldDate)
+	if err != nil {
+		return 0, fmt.Errorf("zillow: last sold date: %w", err)
+	}
+	updated, err := parseDate(z.LastUpdated)
+	if err != nil {
+		return 0, fmt.Errorf("zillow: zestimate last updated: %w", err)
+	}
+	if !updated.After(sold) {
+		return 0, fmt.Errorf("zillow: zestimate last updated %s is not after last sold date %s", z.LastUpdated, soldDate)
+	}
+	years := updated.Sub(sold).Hours() / 24 / 365.25
+	return math.Pow(float64(z.Amount.Value)/float64(soldPrice), 1/years) - 1, nil
+}
+
+// dateLayout is the format of dates such as LastSoldDate.
+const dateLayout = "01/02/2006"
+
+// IsEpochSentinel reports whether date is the Unix epoch, which Zillow reports
+// for unknown dates, e.g. a LastUpdated of "12/31/1969" in Pacific time.
+func IsEpochSentinel(date string) bool {
+	t, err := time.Parse(dateLayout, strings.TrimSpace(date))
+	if err != nil {
+		return false
+	}
+	return t.Equal(time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)) || t.Equal(time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// parseDate parses a date in dateLayout. Missing dates and the epoch
+// sentinel fail with ErrUnknownDate.
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || IsEpochSentinel(s) {
+		return time.Time{}, &UnknownDateError{Date: s}
+	}
+	return time.Parse(dateLayout, s)
+}
+
+// PropertyType returns the parsed UseCode.
+func (f *EditedFacts) PropertyType() PropertyType {
+	return ParsePropertyType(f.UseCode)
+}
+
+// Schools are the schools assigned to a property.
+type Schools struct {
+	District   string
+	Elementary string
+	Middle     string
+	High       string
+}
+
+// IsEmpty reports whether no schools are known.
+func (s Schools) IsEmpty() bool {
+	return s == Schools{}
+}
+
+// UnmarshalXML decodes d as usual, and groups the school fields into Schools.
+func (d *UpdatedPropertyDetails) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	type details UpdatedPropertyDetails
+	var v struct {
+		XMLName xml.Name `xml:"updatedPropertyDetails"`
+		details
+		HighSchool string         `xml:"response>highSchool"`
+		History    []saleEventXML `xml:"response>priceHistory>event"`
+	}
+	if err := dec.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*d = UpdatedPropertyDetails(v.details)
+	d.XMLName = v.XMLName
+	d.Schools = Schools{
+		District:   d.SchoolDistrict,
+		Elementary: d.ElementarySchool,
+		Middle:     d.MiddleSchool,
+		High:       v.HighSchool,
+	}
+	d.PriceHistory = parsePriceHistory(v.History)
+	return nil
+}
+
+// SaleEvent is a dated price event in a property's history, such as a sale
+// or listing.
+type SaleEvent struct {
+	Date  time.Time
+	Price Value
+	// Event is Zillow's description, e.g. "Sold" or "Listed for sale".
+	Event string
+}
+
+type saleEventXML struct {
+	Date  string `xml:"date"`
+	Price Value  `xml:"price"`
+	Event string `xml:"event"`
+}
+
+// parsePriceHistory returns the events in chronological order. Events with
+// an unknown or malformed date can't be ordered and are dropped.
+func parsePriceHistory(events []saleEventXML) []SaleEvent {
+	var history []SaleEvent
+	for _, e := range events {
+		date, err := parseDate(e.Date)
+		if err != nil {
+			continue
+		}
+		history = append(history, SaleEvent{Date: date, Price: e.Price, Event: strings.TrimSpace(e.Event)})
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Date.Before(history[j].Date)
+	})
+	return history
+}
+
+// isSale reports whether e records a sale, rather than e.g. a listing.
+func (e SaleEvent) isSale() bool {
+	return strings.EqualFold(e.Event, "sold")
+}
+
+// LatestSale returns the most recent sale in PriceHistory, or false if there
+// is none.
+func (d *UpdatedPropertyDetails) LatestSale() (SaleEvent, bool) {
+	for i := len(d.PriceHistory) - 1; i >= 0; i-- {
+		if d.PriceHistory[i].isSale() {
+			return d.PriceHistory[i], true
+		}
+	}
+	return SaleEvent{}, false
+}
+
+// LatestSale returns LastSoldPrice and LastSoldDate as a SaleEvent, or false
+// if either is unknown.
+func (r *DeepSearchResult) LatestSale() (SaleEvent, bool) {
+	return lastSale(r.LastSoldPrice, r.LastSoldDate)
+}
+
+// LatestSale is like DeepSearchResult.LatestSale.
+func (c *DeepComp) LatestSale() (SaleEvent, bool) {
+	return lastSale(c.LastSoldPrice, c.LastSoldDate)
+}
+
+func lastSale(price Value, date string) (SaleEvent, bool) {
+	t, err := parseDate(date)
+	if err != nil || price.Value <= 0 {
+		return SaleEvent{}, false
+	}
+	return SaleEvent{Date: t, Price: price, Event: "Sold"}, true
+}
+
+// HomeDetailsURL returns the public zillow.com page for zpid. It makes no API
+// call; the Links of a result carry the same page with the address slug.
+func HomeDetailsURL(zpid string) string {
+	return "https://www.zillow.com/homedetails/" + url.PathEscape(zpid) + "_zpid/"
+}
+
+// Canonicalize returns a copy of l with the standard zillow.com home details,
+// chart, map and comparables pages for zpid filling in any of those links
+// which are empty, relative or contain the <ZWSID> placeholder. Other links
+// are unchanged.
+func (l Links) Canonicalize(zpid string) Links {
+	id := url.PathEscape(zpid)
+	for _, link := range []struct {
+		field *string
+		url   string
+	}{
+		{&l.HomeDetails, HomeDetailsURL(zpid)},
+		{&l.GraphsAndData, "https://www.zillow.com/homedetails/charts/" + id + "_zpid,1year_chartDuration/"},
+		{&l.MapThisHome, "https://www.zillow.com/homes/map/" + id + "_zpid/"},
+		{&l.Comparables, "https://www.zillow.com/homes/comps/" + id + "_zpid/"},
+	} {
+		if !usableLink(*link.field) {
+			*link.field = link.url
+		}
+	}
+	return l
+}
+
+// usableLink reports whether link is an absolute URL without a placeholder.
+func usableLink(link string) bool {
+	if strings.Contains(link, zwsIdPlaceholder) {
+		return false
+	}
+	u, err := url.Parse(link)
+	return err == nil && u.IsAbs() && u.Host != ""
+}
+
+// zwsIdPlaceholder stands in for the partner key in comps links.
+const zwsIdPlaceholder = "<ZWSID>"
+
+// Resolve returns a copy of l with the <ZWSID> placeholder in each link
+// replaced by zwsId. Links without the placeholder are unchanged.
+func (l Links) Resolve(zwsId string) Links {
+	r := strings.NewReplacer(zwsIdPlaceholder, url.QueryEscape(zwsId))
+	l.HomeDetails = r.Replace(l.HomeDetails)
+	l.GraphsAndData = r.Replace(l.GraphsAndData)
+	l.MapThisHome = r.Replace(l.MapThisHome)
+	l.MyZestimator = r.Replace(l.MyZestimator)
+	l.Comparables = r.Replace(l.Comparables)
+	return l
+}
@@ -0,0 +1,288 @@
+package zillow
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePropertyType(t *testing.T) {
+	for useCode, expected := range map[string]PropertyType{
+		"SingleFamily":          SingleFamily,
+		"Single family":         SingleFamily,
+		"single-family":         SingleFamily,
+		"SINGLE_FAMILY":         SingleFamily,
+		"Condominium":           Condominium,
+		"MultiFamily2To4":       Multifamily,
+		"Multi family":          Multifamily,
+		"VacantResidentialLand": VacantLand,
+		"Duplex":                Duplex,
+		"Castle":                UnknownPropertyType,
+		"":                      UnknownPropertyType,
+	} {
+		if actual := ParsePropertyType(useCode); actual != expected {
+			t.Errorf("%q: expected %q but got %q", useCode, expected, actual)
+		}
+	}
+}
+
+func TestPropertyTypeFixtures(t *testing.T) {
+	var deep DeepSearchResults
+	decodeFixture(t, deepSearchPath, &deep)
+	if actual := deep.Results[0].PropertyType(); actual != SingleFamily {
+		t.Errorf("deep search: expected %q but got %q", SingleFamily, actual)
+	}
+
+	var details UpdatedPropertyDetails
+	decodeFixture(t, updatedPropertyDetailsPath, &details)
+	if actual := details.EditedFacts.PropertyType(); actual != SingleFamily {
+		t.Errorf("updated property details: expected %q but got %q", SingleFamily, actual)
+	}
+}
+
+func TestHomeDetailsURL(t *testing.T) {
+	expected := "https://www.zillow.com/homedetails/48749425_zpid/"
+	if actual := HomeDetailsURL(zpid); actual != expected {
+		t.Errorf("expected %q but got %q", expected, actual)
+	}
+}
+
+func TestLinksResolve(t *testing.T) {
+	var result CompsResult
+	decodeFixture(t, compsPath, &result)
+
+	links := result.Comparables[0].Links
+	if !strings.Contains(links.HomeDetails, zwsIdPlaceholder) {
+		t.Fatalf("expected placeholder in fixture link %q", links.HomeDetails)
+	}
+	resolved := links.Resolve(testZwsId)
+	for _, l := range []string{resolved.HomeDetails, resolved.GraphsAndData, resolved.MapThisHome, resolved.MyZestimator, resolved.Comparables} {
+		if strings.Contains(l, zwsIdPlaceholder) {
+			t.Errorf("expected placeholder to be replaced in %q", l)
+		}
+	}
+	if expected := "partner=" + testZwsId; !strings.HasSuffix(resolved.HomeDetails, expected) {
+		t.Errorf("expected %q to end with %q", resolved.HomeDetails, expected)
+	}
+	if !strings.Contains(links.HomeDetails, zwsIdPlaceholder) {
+		t.Error("expected original links to be unchanged")
+	}
+}
+
+func TestLinksCanonicalize(t *testing.T) {
+	canonical := Links{
+		HomeDetails:   "https://www.zillow.com/homedetails/" + zpid + "_zpid/",
+		GraphsAndData: "https://www.zillow.com/homedetails/charts/" + zpid + "_zpid,1year_chartDuration/",
+		MapThisHome:   "https://www.zillow.com/homes/map/" + zpid + "_zpid/",
+		Comparables:   "https://www.zillow.com/homes/comps/" + zpid + "_zpid/",
+	}
+	if actual := (Links{}).Canonicalize(zpid); actual != canonical {
+		t.Errorf("expected:\n %+v\n\n but got:\n %+v", canonical, actual)
+	}
+
+	placeholders := Links{
+		HomeDetails:   "http://www.zillow.com/homedetails/" + zpid + "_zpid/?partner=" + zwsIdPlaceholder,
+		GraphsAndData: "/homedetails/charts/" + zpid + "_zpid/",
+		MapThisHome:   "homes/map/" + zpid + "_zpid/",
+		MyZestimator:  "/myzestimator/" + zpid,
+	}
+	expected := canonical
+	expected.MyZestimator = placeholders.MyZestimator
+	if actual := placeholders.Canonicalize(zpid); actual != expected {
+		t.Errorf("expected:\n %+v\n\n but got:\n %+v", expected, actual)
+	}
+
+	// Usable links are kept.
+	var result ZestimateResult
+	decodeFixture(t, zestimatePath, &result)
+	if actual := result.Links.Canonicalize(zpid); actual != result.Links {
+		t.Errorf("expected:\n %+v\n\n but got:\n %+v", result.Links, actual)
+	}
+}
+
+func TestDeepSearchResultPricePerSqFt(t *testing.T) {
+	var result DeepSearchResults
+	decodeFixture(t, deepSearchPath, &result)
+	r := result.Results[0]
+
+	if ppsf, ok := r.PricePerSqFt(); !ok || ppsf != 1219500.0/3470 {
+		t.Errorf("expected %v but got %v, %t", 1219500.0/3470, ppsf, ok)
+	}
+	if ppsf, ok := r.LastSoldPricePerSqFt(); !ok || ppsf != 995000.0/3470 {
+		t.Errorf("expected %v but got %v, %t", 995000.0/3470, ppsf, ok)
+	}
+
+	r.FinishedSqFt = 0
+	if _, ok := r.PricePerSqFt(); ok {
+		t.Error("expected no price per sqft without sqft")
+	}
+	if _, ok := r.LastSoldPricePerSqFt(); ok {
+		t.Error("expected no last sold price per sqft without sqft")
+	}
+}
+
+func TestAnnualizedAppreciation(t *testing.T) {
+	var comps DeepCompsResult
+	decodeFixture(t, deepCompsPath, &comps)
+	for i, expected := range []float64{
+		// 832500 on 09/24/2009 to 836500 on 11/03/2009.
+		math.Pow(836500.0/832500, 365.25/40) - 1,
+		// 595000 on 08/20/2009 to 608000 on 11/03/2009.
+		math.Pow(608000.0/595000, 365.25/75) - 1,
+	} {
+		actual, err := comps.Comparables[i].AnnualizedAppreciation()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(actual-expected) > 1e-9 {
+			t.Errorf("comp %d: expected %f but got %f", i, expected, actual)
+		}
+	}
+
+	// The principal's zestimate was last updated on the epoch sentinel.
+	var search DeepSearchResults
+	decodeFixture(t, deepSearchPath, &search)
+	if _, err := search.Results[0].AnnualizedAppreciation(); err == nil {
+		t.Error("expected error for epoch last updated date")
+	}
+
+	valid := DeepSearchResult{
+		LastSoldDate:  "11/26/2008",
+		LastSoldPrice: Value{Value: 995000},
+		Zestimate:     Zestimate{Amount: Value{Value: 1219500}, LastUpdated: "11/03/2009"},
+	}
+	if _, err := valid.AnnualizedAppreciation(); err != nil {
+		t.Fatal(err)
+	}
+	for name, modify := range map[string]func(*DeepSearchResult){
+		"no sold price":    func(r *DeepSearchResult) { r.LastSoldPrice.Value = 0 },
+		"no zestimate":     func(r *DeepSearchResult) { r.Zestimate.Amount.Value = 0 },
+		"no sold date":     func(r *DeepSearchResult) { r.LastSoldDate = "" },
+		"epoch sold date":  func(r *DeepSearchResult) { r.LastSoldDate = "12/31/1969" },
+		"malformed date":   func(r *DeepSearchResult) { r.LastSoldDate = "2008-11-26" },
+		"sold after value": func(r *DeepSearchResult) { r.LastSoldDate = "12/01/2009" },
+	} {
+		r := valid
+		modify(&r)
+		if _, err := r.AnnualizedAppreciation(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestUpdatedPropertyDetailsSchools(t *testing.T) {
+	var details UpdatedPropertyDetails
+	decodeFixture(t, "GetUpdatedPropertyDetailsSchools", &details)
+	expected := Schools{District: "Seattle", Elementary: "John Hay", Middle: "McClure", High: "Ballard"}
+	if details.Schools != expected {
+		t.Errorf("expected %+v but got %+v", expected, details.Schools)
+	}
+	if details.Schools.IsEmpty() {
+		t.Error("expected schools not to be empty")
+	}
+	if !details.HasResponse() || details.Address.Street != "2114 Bigelow Ave N" {
+		t.Errorf("expected the rest of the response to decode but got %+v", details)
+	}
+
+	if !(Schools{}).IsEmpty() {
+		t.Error("expected zero schools to be empty")
+	}
+}
+
+func TestPriceHistory(t *testing.T) {
+	var details UpdatedPropertyDetails
+	decodeFixture(t, "GetUpdatedPropertyDetailsHistory", &details)
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	// Zillow lists newest first, and the epoch sentinel is dropped.
+	expected := []SaleEvent{
+		{Date: date(2001, time.March, 14), Price: Value{Currency: "USD", Value: 575000, Present: true}, Event: "Sold"},
+		{Date: date(2008, time.November, 26), Price: Value{Currency: "USD", Value: 1025000, Present: true}, Event: "Sold"},
+		{Date: date(2010, time.June, 1), Price: Value{Currency: "USD", Value: 1395000, Present: true}, Event: "Listed for sale"},
+	}
+	if !reflect.DeepEqual(details.PriceHistory, expected) {
+		t.Errorf("expected %+v but got %+v", expected, details.PriceHistory)
+	}
+	if sale, ok := details.LatestSale(); !ok || sale != expected[1] {
+		t.Errorf("expected latest sale %+v but got %+v, %t", expected[1], sale, ok)
+	}
+
+	var plain UpdatedPropertyDetails
+	decodeFixture(t, updatedPropertyDetailsPath, &plain)
+	if len(plain.PriceHistory) != 0 {
+		t.Errorf("expected no price history but got %+v", plain.PriceHistory)
+	}
+	if sale, ok := plain.LatestSale(); ok {
+		t.Errorf("expected no latest sale but got %+v", sale)
+	}
+
+	var comps DeepCompsResult
+	decodeFixture(t, deepCompsPath, &comps)
+	// The first comparable sold for 832500 on 09/24/2009.
+	sold := SaleEvent{Date: date(2009, time.September, 24), Price: Value{Currency: "USD", Value: 832500, Present: true}, Event: "Sold"}
+	if sale, ok := comps.Comparables[0].LatestSale(); !ok || sale != sold {
+		t.Errorf("expected comp sale %+v but got %+v, %t", sold, sale, ok)
+	}
+	if sale, ok := (&DeepSearchResult{LastSoldDate: "12/31/1969", LastSoldPrice: Value{Value: 995000}}).LatestSale(); ok {
+		t.Errorf("expected no sale for epoch date but got %+v", sale)
+	}
+}
+
+func TestEpochSentinel(t *testing.T) {
+	for date, expected := range map[string]bool{
+		"12/31/1969":   true,
+		"01/01/1970":   true,
+		" 12/31/1969 ": true,
+		"11/03/2009":   false,
+		"12/30/1969":   false,
+		"06/15/1965":   false,
+		"":             false,
+		"1969-12-31":   false,
+	} {
+		if actual := IsEpochSentinel(date); actual != expected {
+			t.Errorf("%q: expected %t but got %t", date, expected, actual)
+		}
+	}
+
+	var zestimate ZestimateResult
+	decodeFixture(t, zestimatePath, &zestimate)
+	if updated, err := zestimate.Zestimate.UpdatedTime(); err != nil || !updated.Equal(time.Date(2009, time.November, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 11/03/2009 but got %s, %v", updated, err)
+	}
+
+	// Deep search and comps results report the sentinel as last updated.
+	var search DeepSearchResults
+	decodeFixture(t, "GetDeepSearchResultsMulti", &search)
+	var comps DeepCompsResult
+	decodeFixture(t, deepCompsPath, &comps)
+	for name, z := range map[string]Zestimate{
+		"search result 0": search.Results[0].Zestimate,
+		"search result 2": search.Results[2].Zestimate,
+		"principal":       comps.Principal.Zestimate,
+	} {
+		if _, err := z.UpdatedTime(); !errors.Is(err, ErrUnknownDate) {
+			t.Errorf("%s: expected ErrUnknownDate but got %v", name, err)
+		}
+	}
+	if _, err := search.Results[0].AnnualizedAppreciation(); !errors.Is(err, ErrUnknownDate) {
+		t.Errorf("expected appreciation to fail with ErrUnknownDate but got %v", err)
+	}
+
+	for date, unknown := range map[string]bool{
+		"12/31/1969": true,
+		"":           true,
+		"2008-11-26": false,
+	} {
+		if _, err := (Zestimate{LastUpdated: date}).UpdatedTime(); err == nil || errors.Is(err, ErrUnknownDate) != unknown {
+			t.Errorf("%q: expected unknown %t but got %v", date, unknown, err)
+		}
+	}
+	// Dates before the epoch are real.
+	if sale, ok := (&DeepSearchResult{LastSoldDate: "06/15/1965", LastSoldPrice: Value{Value: 18000}}).LatestSale(); !ok || sale.Date.Year() != 1965 {
+		t.Errorf("expected a 1965 sale but got %+v, %t", sale, ok)
+	}
+}
@@ -0,0 +1,139 @@
+package zillow
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// RealEstateRegions are the local real estate regions of a property.
+type RealEstateRegions []RealEstateRegion
+
+// trendWeights are the weights of each region type in WeightedTrend.
+var trendWeights = map[string]float64{
+	"neighborhood": 0.5,
+	"city":         0.3,
+	"state":        0.2,
+}
+
+// WeightedTrend combines the one year ZIndex changes of the regions into a
+// single signal of whether the area is appreciating. The neighborhood is
+// weighted 0.5, the city 0.3 and the state 0.2; other region types and
+// regions without a change are ignored, and the weights are scaled up when a
+// type is missing. It returns 0 if there are no weighted regions.
+func (rs RealEstateRegions) WeightedTrend() float64 {
+	var sum, total float64
+	for _, r := range rs {
+		if r.ZIndexOneYearChange == nil {
+			continue
+		}
+		w := trendWeights[r.Type]
+		sum += w * *r.ZIndexOneYearChange
+		total += w
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// UniqueRegions returns the local real estate regions of all the results,
+// once each by ID, in the order they first appear. Regions without an ID are
+// all kept.
+func (r *SearchResults) UniqueRegions() []RealEstateRegion {
+	var regions []RealEstateRegion
+	seen := make(map[string]bool)
+	for _, result := range r.Results {
+		for _, region := range result.LocalRealEstate {
+			if region.ID != "" {
+				if seen[region.ID] {
+					continue
+				}
+				seen[region.ID] = true
+			}
+			regions = append(regions, region)
+		}
+	}
+	return regions
+}
+
+// NameToID maps the name of each child region to its id. If names repeat,
+// the last region with the name wins.
+func (r *RegionChildren) NameToID() map[string]string {
+	m := make(map[string]string, len(r.Regions))
+	for _, region := range r.Regions {
+		m[region.Name] = region.Id
+	}
+	return m
+}
+
+// IDToRegion maps the id of each child region to the region.
+func (r *RegionChildren) IDToRegion() map[string]Region {
+	m := make(map[string]Region, len(r.Regions))
+	for _, region := range r.Regions {
+		m[region.Id] = region
+	}
+	return m
+}
+
+// subregionTypes maps a region type to the type of the regions within it
+// which GetAllRegionChildren requests next.
+var subregionTypes = map[string]string{
+	"country": "state",
+	"state":   "county",
+	"county":  "city",
+	"city":    "neighborhood",
+}
+
+// regionChildrenConcurrency is the most child region calls
+// GetAllRegionChildren has in flight.
+const regionChildrenConcurrency = 4
+
+func (z *zillow) GetAllRegionChildren(ctx context.Context, request RegionChildrenRequest) (*RegionChildren, error) {
+	result, err := z.regionChildren(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if result.Message.Err() != nil {
+		return result, nil
+	}
+	childType := ""
+	if request.ChildType != "" {
+		var ok bool
+		if childType, ok = subregionTypes[strings.ToLower(request.ChildType)]; !ok {
+			// The children have no subregions.
+			return result, nil
+		}
+	}
+	seen := make(map[string]bool, len(result.Regions))
+	for _, r := range result.Regions {
+		seen[r.Id] = true
+	}
+	children := result.Regions
+	grandchildren := make([]*RegionChildren, len(children))
+	errs := z.batch(ctx, len(children), regionChildrenConcurrency, func(ctx context.Context, i int) (err error) {
+		// The child's id replaces the city, but the state and country still
+		// apply.
+		child := request
+		child.RegionId, child.City, child.ChildType = children[i].Id, "", childType
+		grandchildren[i], err = z.regionChildren(ctx, child)
+		return
+	})
+	for i, g := range grandchildren {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if err := g.Message.Err(); errors.Is(err, ErrNoMatch) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, r := range g.Regions {
+			if !seen[r.Id] {
+				seen[r.Id] = true
+				result.Regions = append(result.Regions, r)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,172 @@
+package zillow
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWeightedTrend(t *testing.T) {
+	var result ZestimateResult
+	decodeFixture(t, zestimatePath, &result)
+
+	expected := 0.5*-0.144 + 0.3*-0.074 + 0.2*-0.066
+	if actual := result.LocalRealEstate.WeightedTrend(); math.Abs(actual-expected) > 1e-9 {
+		t.Errorf("expected %v but got %v", expected, actual)
+	}
+
+	// Without the neighborhood, the city and state are weighted 0.6 and 0.4.
+	expected = 0.6*-0.074 + 0.4*-0.066
+	if actual := result.LocalRealEstate[1:].WeightedTrend(); math.Abs(actual-expected) > 1e-9 {
+		t.Errorf("expected %v but got %v", expected, actual)
+	}
+
+	if actual := (RealEstateRegions{}).WeightedTrend(); actual != 0 {
+		t.Errorf("expected 0 but got %v", actual)
+	}
+}
+
+func TestZIndexOneYearChange(t *testing.T) {
+	var zestimate ZestimateResult
+	decodeFixture(t, zestimatePath, &zestimate)
+	if c := zestimate.LocalRealEstate[0].ZIndexOneYearChange; c == nil || *c != -0.144 {
+		t.Errorf("expected -0.144 but got %v", c)
+	}
+
+	var deep DeepSearchResults
+	decodeFixture(t, deepSearchPath, &deep)
+	if c := deep.Results[0].LocalRealEstate[0].ZIndexOneYearChange; c != nil {
+		t.Errorf("expected no change but got %v", *c)
+	}
+
+	var zero RealEstateRegion
+	if err := decodeXML([]byte(`<region type="city"><zindexOneYearChange>0</zindexOneYearChange></region>`), &zero); err != nil {
+		t.Fatal(err)
+	}
+	if c := zero.ZIndexOneYearChange; c == nil || *c != 0 {
+		t.Errorf("expected a present 0 but got %v", c)
+	}
+
+	// Regions without a change don't count towards the trend.
+	regions := append(RealEstateRegions{deep.Results[0].LocalRealEstate[0]}, zestimate.LocalRealEstate[1:]...)
+	expected := 0.6*-0.074 + 0.4*-0.066
+	if actual := regions.WeightedTrend(); math.Abs(actual-expected) > 1e-9 {
+		t.Errorf("expected %v but got %v", expected, actual)
+	}
+}
+
+func TestRegionChildrenMaps(t *testing.T) {
+	var result RegionChildren
+	decodeFixture(t, regionChildrenPath, &result)
+
+	expected := map[string]string{"Alki": "343997", "Greenwood": "250788", "Wallingford": "252248"}
+	if actual := result.NameToID(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v but got %v", expected, actual)
+	}
+	regions := result.IDToRegion()
+	if len(regions) != 3 || regions["250788"].Name != "Greenwood" {
+		t.Errorf("expected regions keyed by id but got %v", regions)
+	}
+
+	// The last of a repeated name wins.
+	result.Regions = append(result.Regions, Region{Id: "999", Name: "Alki"})
+	if id := result.NameToID()["Alki"]; id != "999" {
+		t.Errorf("expected the last Alki but got %q", id)
+	}
+}
+
+func TestGetAllRegionChildren(t *testing.T) {
+	const countyId = "207"
+	var calls, inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		values := r.URL.Query()
+		assertOnlyParam(t, values, stateParam, regionState)
+		assertOnlyParam(t, values, cityParam, "")
+		// The county's cities, then the neighborhoods of each city.
+		fixture := "GetRegionChildrenNoMatch"
+		switch id := values.Get(regionIdParam); id {
+		case countyId:
+			assertOnlyParam(t, values, childTypeParam, "city")
+			fixture = "GetRegionChildrenCities"
+		default:
+			assertOnlyParam(t, values, childTypeParam, childType)
+			switch id {
+			case "16037":
+				fixture = regionChildrenPath
+			case "8010":
+				fixture = "GetRegionChildrenNested"
+			}
+		}
+		http.ServeFile(w, r, "testdata/"+fixture+".xml")
+	}))
+	defer server.Close()
+
+	z := &zillow{zwsId: testZwsId, url: server.URL}
+	result, err := z.GetAllRegionChildren(context.Background(), RegionChildrenRequest{RegionId: countyId, State: regionState, ChildType: "city"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, r := range result.Regions {
+		names = append(names, r.Name)
+	}
+	// Greenwood is listed under Bellevue too, but only kept once.
+	expected := []string{"Seattle", "Bellevue", "Redmond", "Alki", "Greenwood", "Wallingford", "Alki Point", "Admiral"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v but got %v", expected, names)
+	}
+	if calls != 4 {
+		t.Errorf("expected a call for the county and each of its 3 cities but got %d", calls)
+	}
+	if maxInFlight > regionChildrenConcurrency {
+		t.Errorf("expected at most %d calls in flight but got %d", regionChildrenConcurrency, maxInFlight)
+	}
+
+	// Neighborhoods have no level below.
+	var observed int
+	server, client := testFixture(t, regionChildrenPath, regionChildrenPath, func(url.Values) {}, WithObserver(func(CallStats) { observed++ }))
+	defer server.Close()
+	result, err = client.GetAllRegionChildren(context.Background(), RegionChildrenRequest{City: regionCity, State: regionState, ChildType: childType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Regions) != 3 || observed != 1 {
+		t.Errorf("expected the 3 neighborhoods from a single call but got %d regions from %d calls", len(result.Regions), observed)
+	}
+}
+
+func TestSearchResultsUniqueRegions(t *testing.T) {
+	var result SearchResults
+	decodeFixture(t, "GetSearchResultsMulti", &result)
+	// The results share the city and state, but not the neighborhood.
+	var ids []string
+	for _, r := range result.UniqueRegions() {
+		ids = append(ids, r.ID)
+	}
+	if expected := []string{"271856", "16037", "59", "271859"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v but got %v", expected, ids)
+	}
+	for i, r := range result.Results {
+		if len(r.LocalRealEstate) != 3 {
+			t.Errorf("result %d: expected regions to be left as is but got %d", i, len(r.LocalRealEstate))
+		}
+	}
+
+	if regions := (&SearchResults{}).UniqueRegions(); len(regions) != 0 {
+		t.Errorf("expected no regions but got %v", regions)
+	}
+}
@@ -0,0 +1,142 @@
+package zillow
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Request is implemented by every request type.
+type Request interface {
+	// Encode returns the query parameters of the request, except the zws-id.
+	Encode() url.Values
+}
+
+// values encodes request with the client's zws-id and defaults.
+func (z *zillow) values(request Request) url.Values {
+	values := z.effective(request).Encode()
+	values.Set(zwsIdParam, z.zwsId)
+	return values
+}
+
+// effective returns request with the client's defaults applied.
+func (z *zillow) effective(request Request) Request {
+	if r, ok := request.(rentRequest); ok && z.defaultRentZestimate {
+		return r.withDefaultRent()
+	}
+	return request
+}
+
+// rentRequest is implemented by requests which take the default rent
+// zestimate.
+type rentRequest interface {
+	// withDefaultRent returns the request asking for rent data, unless it
+	// opted out.
+	withDefaultRent() Request
+}
+
+func (r ZestimateRequest) withDefaultRent() Request {
+	r.Rentzestimate = r.Rentzestimate || !r.NoRentzestimate
+	return r
+}
+
+func (r SearchRequest) withDefaultRent() Request {
+	r.Rentzestimate = r.Rentzestimate || !r.NoRentzestimate
+	return r
+}
+
+func (r CompsRequest) withDefaultRent() Request {
+	r.Rentzestimate = r.Rentzestimate || !r.NoRentzestimate
+	return r
+}
+
+// encodeParams encodes each field of req, a request struct, as the query
+// parameter named by its xml tag.
+func encodeParams(req interface{}) url.Values {
+	values := url.Values{}
+	v := reflect.Indirect(reflect.ValueOf(req))
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if name := paramName(t.Field(i)); name != "" {
+			values.Set(name, formatParam(v.Field(i)))
+		}
+	}
+	return values
+}
+
+// paramName returns the query parameter name of f, or "" if it has none.
+func paramName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+	name := strings.Split(f.Tag.Get("xml"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// formatParam formats v as a query parameter value. Floats use the fewest
+// digits that represent them exactly at their precision, so float32(6.1) is
+// "6.1".
+func formatParam(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	panic(fmt.Sprintf("zillow: can't encode %s as a query parameter", v.Type()))
+}
+
+func (r ZestimateRequest) Encode() url.Values {
+	return encodeParams(r)
+}
+
+func (r SearchRequest) Encode() url.Values {
+	return encodeParams(r)
+}
+
+func (r ChartRequest) Encode() url.Values {
+	return encodeParams(r)
+}
+
+func (r CompsRequest) Encode() url.Values {
+	return encodeParams(r)
+}
+
+func (r UpdatedPropertyDetailsRequest) Encode() url.Values {
+	return encodeParams(r)
+}
+
+func (r RegionChildrenRequest) Encode() url.Values {
+	return encodeParams(r)
+}
+
+func (r RegionChartRequest) Encode() url.Values {
+	return encodeParams(r)
+}
+
+func (r RateSummaryRequest) Encode() url.Values {
+	return encodeParams(r)
+}
+
+func (r MonthlyPaymentsRequest) Encode() url.Values {
+	return encodeParams(r)
+}
+
+func (r MonthlyPaymentsAdvancedRequest) Encode() url.Values {
+	return encodeParams(r)
+}
+
+func (r AffordabilityRequest) Encode() url.Values {
+	return encodeParams(r)
+}
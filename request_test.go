@@ -0,0 +1,125 @@
+package zillow
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestRequestEncode(t *testing.T) {
+	for _, c := range []struct {
+		request  Request
+		expected url.Values
+	}{
+		{ZestimateRequest{Zpid: zpid, Rentzestimate: true}, url.Values{
+			"zpid":          {zpid},
+			"rentzestimate": {"true"},
+		}},
+		{SearchRequest{Address: address, CityStateZip: citystatezip}, url.Values{
+			"address":       {address},
+			"citystatezip":  {citystatezip},
+			"rentzestimate": {"false"},
+		}},
+		{ChartRequest{Zpid: zpid, UnitType: unitType, Width: width, Height: height, Duration: "5years"}, url.Values{
+			"zpid":          {zpid},
+			"unit-type":     {unitType},
+			"width":         {"300"},
+			"height":        {"150"},
+			"chartDuration": {"5years"},
+		}},
+		{CompsRequest{Zpid: zpid, Count: count}, url.Values{
+			"zpid":          {zpid},
+			"count":         {"5"},
+			"rentzestimate": {"false"},
+		}},
+		{UpdatedPropertyDetailsRequest{Zpid: zpid}, url.Values{
+			"zpid": {zpid},
+		}},
+		{RegionChildrenRequest{RegionId: "16037", State: regionState, Country: "usa", City: regionCity, ChildType: childType}, url.Values{
+			"regionId":  {"16037"},
+			"state":     {regionState},
+			"country":   {"usa"},
+			"city":      {regionCity},
+			"childtype": {childType},
+		}},
+		{RegionChartRequest{City: city, State: state, Neighborhood: "Downtown", Zipcode: zip, UnitType: unitType, Width: width, Height: height, ChartDuration: "1year"}, url.Values{
+			"city":          {city},
+			"state":         {state},
+			"neighborhood":  {"Downtown"},
+			"zip":           {zip},
+			"unit-type":     {unitType},
+			"width":         {"300"},
+			"height":        {"150"},
+			"chartDuration": {"1year"},
+		}},
+		{RateSummaryRequest{State: state}, url.Values{
+			"state": {state},
+		}},
+		{MonthlyPaymentsRequest{Price: price, Down: down, DollarsDown: 45000, Zip: zip}, url.Values{
+			"price":       {"300000"},
+			"down":        {"15"},
+			"dollarsdown": {"45000"},
+			"zip":         {zip},
+		}},
+		{MonthlyPaymentsAdvancedRequest{Price: price, Down: down, Amount: 255000, Rate: rate, Schedule: schedule, TermInMonths: termInMonths, PropertyTax: propertyTax, Hazard: hazard, PMI: pmi, HOA: hoa, Zip: zip}, url.Values{
+			"price":        {"300000"},
+			"down":         {"15"},
+			"amount":       {"255000"},
+			"rate":         {"6"},
+			"schedule":     {schedule},
+			"terminmonths": {"360"},
+			"propertytax":  {"2000"},
+			"hazard":       {"1000"},
+			"pmi":          {"150"},
+			"hoa":          {"3200"},
+			"zip":          {zip},
+		}},
+		{AffordabilityRequest{AnnualIncome: annualIncome, MonthlyPayment: monthlyPayment, Down: down, MonthlyDebts: monthlyDebts, Rate: 6.25, Schedule: schedule, TermInMonths: termInMonths, DebtToIncome: debtToIncome, IncomeTax: incomeTax, Estimate: true, PropertyTax: 1.2, Hazard: hazard, PMI: pmi, HOA: hoa, Zip: zip}, url.Values{
+			"annualincome":   {"1000000"},
+			"monthlypayment": {"2000"},
+			"down":           {"15"},
+			"monthlydebts":   {"1500"},
+			"rate":           {"6.25"},
+			"schedule":       {schedule},
+			"terminmonths":   {"360"},
+			"debttoincome":   {"36"},
+			"incometax":      {"30"},
+			"estimate":       {"true"},
+			"propertytax":    {"1.2"},
+			"hazard":         {"1000"},
+			"pmi":            {"150"},
+			"hoa":            {"3200"},
+			"zip":            {zip},
+		}},
+	} {
+		if actual := c.request.Encode(); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%T: expected:\n %v\n\n but got:\n %v", c.request, c.expected, actual)
+		}
+	}
+}
+
+func TestEncodeParams(t *testing.T) {
+	type request struct {
+		S       string  `xml:"s"`
+		I       int     `xml:"i"`
+		B       bool    `xml:"b"`
+		F32     float32 `xml:"f32"`
+		F64     float64 `xml:"f64"`
+		P       Percent `xml:"p"`
+		Skipped string  `xml:"-"`
+		Untyped string
+		private string
+	}
+	actual := encodeParams(&request{S: "a b", I: -3, B: true, F32: 6.1, F64: 0.125, P: 36.5, Skipped: "x", Untyped: "y", private: "z"})
+	expected := url.Values{
+		"s":   {"a b"},
+		"i":   {"-3"},
+		"b":   {"true"},
+		"f32": {"6.1"},
+		"f64": {"0.125"},
+		"p":   {"36.5"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected:\n %v\n\n but got:\n %v", expected, actual)
+	}
+}
@@ -0,0 +1,230 @@
+package zillow
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"reflect"
+	"time"
+)
+
+type retryPolicy struct {
+	maxAttempts int
+	// backoff returns the delay before each retry, drawing any jitter from
+	// the client's source, or is nil for the default.
+	backoff func(jitter func() float64) func(attempt int) time.Duration
+	retryIf func(endpoint string, err error) bool
+}
+
+// RetryOption configures the retry behavior enabled by WithRetry.
+type RetryOption func(*retryPolicy)
+
+// WithRetry makes calls that fail with a transient error (a network timeout or
+// a truncated response body) retry, up to maxAttempts attempts in total. Calls
+// whose result message reports a service error (code 1) or unavailability
+// (code 3) are retried too; if every attempt does, the last result is
+// returned as usual. Retries are delayed by DefaultBackoff(200ms, 10s) unless
+// RetryBackoff is given, with jitter drawn from a source seeded from the clock,
+// so a fixed clock set by WithClock, or a source set by WithRandSource, makes
+// the delays deterministic.
+func WithRetry(maxAttempts int, opts ...RetryOption) Option {
+	return func(z *zillow) {
+		p := &retryPolicy{maxAttempts: maxAttempts}
+		for _, opt := range opts {
+			opt(p)
+		}
+		z.retry = p
+	}
+}
+
+// WithRetryBudget caps the time a single batch call, such as GetZestimates,
+// spends retrying: no retry starts later than d after the batch call began,
+// nor one whose backoff would end later. Once the budget is spent, the call's
+// last error or result is returned. It has no effect without WithRetry, or
+// outside batch calls.
+func WithRetryBudget(d time.Duration) Option {
+	return func(z *zillow) {
+		z.retryBudget = d
+	}
+}
+
+type retryBudgetKey struct{}
+
+// withRetryBudget returns a ctx carrying a retry deadline d from now, or ctx if
+// d isn't positive.
+func withRetryBudget(ctx context.Context, d time.Duration) context.Context {
+	if d <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, retryBudgetKey{}, time.Now().Add(d))
+}
+
+// takeRetryBudget reports whether a retry after a backoff of d ends within the
+// retry deadline of ctx. Without a deadline it always does.
+func takeRetryBudget(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Value(retryBudgetKey{}).(time.Time)
+	return !ok || !time.Now().Add(d).After(deadline)
+}
+
+// RetryBackoff sets the delay before each retry. attempt is the number of the
+// attempt which just failed, starting from 1. A backoff returning zero retries
+// immediately.
+func RetryBackoff(backoff func(attempt int) time.Duration) RetryOption {
+	return func(p *retryPolicy) {
+		p.backoff = nil
+		if backoff != nil {
+			p.backoff = bindBackoff(backoff)
+		}
+	}
+}
+
+// RetryIf restricts retries to the transient errors for which retryIf returns
+// true. endpoint is the API name, e.g. "CalculateAffordability", so calls to
+// particular endpoints can be excluded. By default every endpoint is retried.
+func RetryIf(retryIf func(endpoint string, err error) bool) RetryOption {
+	return func(p *retryPolicy) {
+		p.retryIf = retryIf
+	}
+}
+
+// shouldRetry reports whether a call to endpoint which failed with err on
+// attempt should be attempted again. A nil policy never retries.
+func (p *retryPolicy) shouldRetry(endpoint string, attempt int, err error) bool {
+	if p == nil || attempt >= p.maxAttempts || !isTransient(err) {
+		return false
+	}
+	return p.retryIf == nil || p.retryIf(endpoint, err)
+}
+
+// Default retry backoff bounds.
+const (
+	defaultBackoffBase = 200 * time.Millisecond
+	defaultBackoffMax  = 10 * time.Second
+)
+
+// DefaultBackoff returns an exponential backoff for RetryBackoff: the delay
+// after attempt n is between half and all of base*2^(n-1), capped at max, or
+// zero if base isn't positive. Given to RetryBackoff, its jitter comes from the
+// client's source, as set by WithRandSource or seeded from WithClock; called
+// directly, it comes from math/rand.
+func DefaultBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return (&defaultBackoff{base: base, max: max}).delay
+}
+
+// defaultBackoff holds the bounds of a DefaultBackoff.
+type defaultBackoff struct {
+	base, max time.Duration
+}
+
+// Attempts for which the delay of a defaultBackoff is its base or max, so that
+// bindBackoff can recover them. Real attempts start from 1.
+const (
+	probeBackoffBase = math.MinInt32
+	probeBackoffMax  = math.MinInt32 + 1
+)
+
+func (b *defaultBackoff) delay(attempt int) time.Duration {
+	switch attempt {
+	case probeBackoffBase:
+		return b.base
+	case probeBackoffMax:
+		return b.max
+	}
+	return exponentialBackoff(b.base, b.max, rand.Float64)(attempt)
+}
+
+// defaultBackoffCode identifies the functions returned by DefaultBackoff, which
+// all share the code of the defaultBackoff.delay method value.
+var defaultBackoffCode = reflect.ValueOf((&defaultBackoff{}).delay).Pointer()
+
+// bindBackoff returns a function binding backoff to a client's jitter. A
+// DefaultBackoff is rebuilt to draw from it; other backoffs are used as is.
+func bindBackoff(backoff func(attempt int) time.Duration) func(jitter func() float64) func(attempt int) time.Duration {
+	if reflect.ValueOf(backoff).Pointer() == defaultBackoffCode {
+		base, max := backoff(probeBackoffBase), backoff(probeBackoffMax)
+		return func(jitter func() float64) func(attempt int) time.Duration {
+			return exponentialBackoff(base, max, jitter)
+		}
+	}
+	return func(func() float64) func(attempt int) time.Duration {
+		return backoff
+	}
+}
+
+// exponentialBackoff is DefaultBackoff with jitter drawn from random, which
+// returns values in [0, 1).
+func exponentialBackoff(base, max time.Duration, random func() float64) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		if base <= 0 {
+			return 0
+		}
+		d := max
+		if shift := uint(attempt - 1); attempt >= 1 && shift < 63 {
+			if e := base << shift; e > 0 && e>>shift == base && e < max {
+				d = e
+			}
+		}
+		half := d / 2
+		return half + time.Duration(random()*float64(d-half))
+	}
+}
+
+// WithRandSource sets the source of the jitter in the default retry backoff,
+// and in a DefaultBackoff given to RetryBackoff, e.g. rand.NewSource(1) for
+// reproducible delays. The default is a source seeded from the clock. The
+// source is only used under a lock, so it needn't be safe for concurrent use.
+func WithRandSource(src rand.Source) Option {
+	return func(z *zillow) {
+		z.rand = rand.New(src)
+	}
+}
+
+// jitter returns a random value in [0, 1) from the WithRandSource source, or
+// one seeded from the clock on first use.
+func (z *zillow) jitter() float64 {
+	z.randMu.Lock()
+	defer z.randMu.Unlock()
+	if z.rand == nil {
+		z.rand = rand.New(rand.NewSource(z.now().UnixNano()))
+	}
+	return z.rand.Float64()
+}
+
+// delay returns the backoff after attempt. jitter is used for the default
+// backoff, and by DefaultBackoff when given to RetryBackoff.
+func (p *retryPolicy) delay(attempt int, jitter func() float64) time.Duration {
+	if p.backoff == nil {
+		return exponentialBackoff(defaultBackoffBase, defaultBackoffMax, jitter)(attempt)
+	}
+	return p.backoff(jitter)(attempt)
+}
+
+// wait sleeps for the delay after attempt, and reports false if ctx was done
+// first, or its retry budget can't cover the delay.
+func (p *retryPolicy) wait(ctx context.Context, attempt int, jitter func() float64) bool {
+	d := p.delay(attempt, jitter)
+	if !takeRetryBudget(ctx, d) {
+		return false
+	}
+	if d <= 0 {
+		return true
+	}
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isTransient reports whether err is likely to go away if the call is repeated.
+func isTransient(err error) bool {
+	var r interface{ Retryable() bool }
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,309 @@
+package zillow
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// truncatingServer serves the fixture for path, but the first failures
+// responses are cut off halfway and the connection closed.
+func truncatingServer(t *testing.T, path string, failures int) (*httptest.Server, *int) {
+	fixture, err := ioutil.ReadFile("testdata/" + path + ".xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls int
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls > failures {
+			w.Write(fixture)
+			return
+		}
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: " + strconv.Itoa(len(fixture)) + "\r\n\r\n")
+		buf.Write(fixture[:len(fixture)/2])
+		buf.Flush()
+	})), &calls
+}
+
+func TestTruncatedBodyRetryable(t *testing.T) {
+	server, _ := truncatingServer(t, zestimatePath, 1)
+	defer server.Close()
+
+	z := &zillow{zwsId: testZwsId, url: server.URL}
+	_, err := z.GetZestimate(ZestimateRequest{Zpid: zpid})
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected DecodeError but got %v", err)
+	}
+	if !decodeErr.Retryable() {
+		t.Fatalf("expected truncated body to be retryable: %v", err)
+	}
+}
+
+func TestRetryTruncatedBody(t *testing.T) {
+	server, calls := truncatingServer(t, zestimatePath, 1)
+	defer server.Close()
+
+	var backoffs []int
+	z := &zillow{zwsId: testZwsId, url: server.URL}
+	WithRetry(3, RetryBackoff(func(attempt int) time.Duration {
+		backoffs = append(backoffs, attempt)
+		return time.Millisecond
+	}))(z)
+	result, err := z.GetZestimate(ZestimateRequest{Zpid: zpid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Zestimate.Amount.Value != 1219500 {
+		t.Fatalf("expected complete result but got %#v", result)
+	}
+	if *calls != 2 {
+		t.Fatalf("expected 2 calls but got %d", *calls)
+	}
+	if len(backoffs) != 1 || backoffs[0] != 1 {
+		t.Fatalf("expected a single backoff after attempt 1 but got %v", backoffs)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	server, calls := truncatingServer(t, zestimatePath, 5)
+	defer server.Close()
+
+	z := &zillow{zwsId: testZwsId, url: server.URL}
+	WithRetry(2)(z)
+	if _, err := z.GetZestimate(ZestimateRequest{Zpid: zpid}); err == nil {
+		t.Fatal("expected error")
+	}
+	if *calls != 2 {
+		t.Fatalf("expected 2 calls but got %d", *calls)
+	}
+}
+
+func TestRetryIf(t *testing.T) {
+	for _, path := range []string{zestimatePath, affordabilityPath} {
+		server, calls := truncatingServer(t, path, 1)
+		var endpoints []string
+		z := &zillow{zwsId: testZwsId, url: server.URL}
+		WithRetry(3, RetryIf(func(endpoint string, err error) bool {
+			endpoints = append(endpoints, endpoint)
+			return endpoint != affordabilityPath
+		}))(z)
+
+		var err error
+		if path == zestimatePath {
+			_, err = z.GetZestimate(ZestimateRequest{Zpid: zpid})
+		} else {
+			_, err = z.CalculateAffordability(AffordabilityRequest{AnnualIncome: 100000, Down: 20000, MonthlyDebts: 1500, Rate: rate, Schedule: schedule, TermInMonths: termInMonths})
+		}
+		server.Close()
+
+		if len(endpoints) != 1 || endpoints[0] != path {
+			t.Errorf("%s: expected predicate to be called once with the endpoint but got %v", path, endpoints)
+		}
+		if path == affordabilityPath {
+			if err == nil || *calls != 1 {
+				t.Errorf("%s: expected a single failed call but got %d calls and error %v", path, *calls, err)
+			}
+		} else if err != nil || *calls != 2 {
+			t.Errorf("%s: expected a retried success but got %d calls and error %v", path, *calls, err)
+		}
+	}
+}
+
+// sequenceServer serves testdata/<fixture>.xml for each fixture in turn,
+// repeating the last.
+func sequenceServer(t *testing.T, fixtures ...string) (*httptest.Server, *int) {
+	var calls int
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture := fixtures[len(fixtures)-1]
+		if calls < len(fixtures) {
+			fixture = fixtures[calls]
+		}
+		calls++
+		http.ServeFile(w, r, "testdata/"+fixture+".xml")
+	})), &calls
+}
+
+func TestRetryTransientMessage(t *testing.T) {
+	server, calls := sequenceServer(t, "GetZestimateUnavailable", zestimatePath)
+	defer server.Close()
+
+	z := &zillow{zwsId: testZwsId, url: server.URL}
+	WithRetry(3)(z)
+	result, err := z.GetZestimate(ZestimateRequest{Zpid: zpid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Message.Code != 0 {
+		t.Fatalf("expected success but got %#v", result.Message)
+	}
+	if *calls != 2 {
+		t.Fatalf("expected 2 calls but got %d", *calls)
+	}
+}
+
+func TestRetryTransientMessageExhausted(t *testing.T) {
+	server, calls := sequenceServer(t, "GetZestimateUnavailable")
+	defer server.Close()
+
+	z := &zillow{zwsId: testZwsId, url: server.URL}
+	WithRetry(2)(z)
+	result, err := z.GetZestimate(ZestimateRequest{Zpid: zpid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Message.Code != codeServiceUnavailable {
+		t.Fatalf("expected the last result but got %#v", result.Message)
+	}
+	if *calls != 2 {
+		t.Fatalf("expected 2 calls but got %d", *calls)
+	}
+}
+
+func TestNoRetryPermanentMessage(t *testing.T) {
+	for _, fixture := range []string{"GetZestimateNoMatch", "GetZestimateNotEntitled"} {
+		server, calls := sequenceServer(t, fixture, zestimatePath)
+		z := &zillow{zwsId: testZwsId, url: server.URL}
+		WithRetry(3)(z)
+		result, err := z.GetZestimate(ZestimateRequest{Zpid: zpid})
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Message.Code == 0 {
+			t.Errorf("%s: expected the failure to be returned but got %#v", fixture, result.Message)
+		}
+		if *calls != 1 {
+			t.Errorf("%s: expected 1 call but got %d", fixture, *calls)
+		}
+	}
+}
+
+func TestDefaultBackoff(t *testing.T) {
+	base, max := 100*time.Millisecond, 2*time.Second
+	backoff := DefaultBackoff(base, max)
+	for i, expected := range []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		1600 * time.Millisecond,
+		max,
+		max,
+	} {
+		attempt := i + 1
+		for i := 0; i < 100; i++ {
+			if d := backoff(attempt); d < expected/2 || d > expected {
+				t.Fatalf("attempt %d: expected between %s and %s but got %s", attempt, expected/2, expected, d)
+			}
+		}
+	}
+	for _, attempt := range []int{64, 100, 1 << 30} {
+		if d := backoff(attempt); d < max/2 || d > max {
+			t.Errorf("attempt %d: expected at most %s but got %s", attempt, max, d)
+		}
+	}
+	for _, base := range []time.Duration{0, -time.Second} {
+		for _, attempt := range []int{1, 2, 64} {
+			if d := DefaultBackoff(base, max)(attempt); d != 0 {
+				t.Errorf("base %s, attempt %d: expected no delay but got %s", base, attempt, d)
+			}
+		}
+	}
+
+	// Used by a client, the jitter comes from the client's clock.
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	a, b := &zillow{}, &zillow{}
+	for _, z := range []*zillow{a, b} {
+		WithRetry(8, RetryBackoff(backoff))(z)
+		WithClock(func() time.Time { return now })(z)
+	}
+	for attempt := 1; attempt <= 8; attempt++ {
+		if x, y := a.retry.delay(attempt, a.jitter), b.retry.delay(attempt, b.jitter); x != y {
+			t.Errorf("attempt %d: expected identical delays from identical clocks but got %s and %s", attempt, x, y)
+		}
+	}
+}
+
+func TestRetryJitterSeededFromClock(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := func() time.Time { return now }
+	a, b := &zillow{}, &zillow{}
+	WithClock(clock)(a)
+	WithClock(clock)(b)
+	for i := 0; i < 10; i++ {
+		if x, y := a.jitter(), b.jitter(); x != y {
+			t.Fatalf("expected identical jitter from identical clocks but got %f and %f", x, y)
+		}
+	}
+}
+
+func TestWithRandSource(t *testing.T) {
+	const seed = 7
+	a, b := &zillow{}, &zillow{}
+	for _, z := range []*zillow{a, b} {
+		WithRetry(8)(z)
+		WithRandSource(rand.NewSource(seed))(z)
+	}
+	expected := rand.New(rand.NewSource(seed))
+	for attempt := 1; attempt <= 8; attempt++ {
+		// The default backoff doubles from 200ms, capped at 10s, and is
+		// jittered down by up to half.
+		d := defaultBackoffMax
+		if e := defaultBackoffBase << uint(attempt-1); e < d {
+			d = e
+		}
+		exact := d/2 + time.Duration(expected.Float64()*float64(d-d/2))
+		if x, y := a.retry.delay(attempt, a.jitter), b.retry.delay(attempt, b.jitter); x != exact || y != exact {
+			t.Errorf("attempt %d: expected %s from both clients but got %s and %s", attempt, exact, x, y)
+		}
+	}
+
+	// A DefaultBackoff given to RetryBackoff draws from the source too.
+	base, max := 100*time.Millisecond, 2*time.Second
+	z := &zillow{}
+	WithRetry(8, RetryBackoff(DefaultBackoff(base, max)))(z)
+	WithRandSource(rand.NewSource(seed))(z)
+	expected = rand.New(rand.NewSource(seed))
+	for attempt := 1; attempt <= 8; attempt++ {
+		d := max
+		if e := base << uint(attempt-1); e < d {
+			d = e
+		}
+		exact := d/2 + time.Duration(expected.Float64()*float64(d-d/2))
+		if got := z.retry.delay(attempt, z.jitter); got != exact {
+			t.Errorf("attempt %d: expected %s but got %s", attempt, exact, got)
+		}
+	}
+}
+
+func TestRetryBackoffIndependentClients(t *testing.T) {
+	// A backoff may consult another client's policy without blocking on it.
+	inner := &zillow{}
+	WithRetry(2, RetryBackoff(DefaultBackoff(time.Millisecond, time.Second)))(inner)
+	outer := &zillow{}
+	WithRetry(2, RetryBackoff(func(attempt int) time.Duration {
+		return 2 * inner.retry.delay(attempt, inner.jitter)
+	}))(outer)
+	done := make(chan time.Duration)
+	go func() { done <- outer.retry.delay(1, outer.jitter) }()
+	select {
+	case d := <-done:
+		if d < time.Millisecond || d > 2*time.Millisecond {
+			t.Errorf("expected between 1ms and 2ms but got %s", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected nested backoffs not to block")
+	}
+}
@@ -0,0 +1,100 @@
+package zillow
+
+import "strings"
+
+// EnrichedResult joins the shallow and deep search results for a zpid. Either
+// side is nil when only one of the searches returned the property.
+type EnrichedResult struct {
+	Zpid    string
+	Shallow *SearchResult
+	Deep    *DeepSearchResult
+}
+
+// MergeSearchResults joins shallow and deep search results by zpid. Results are
+// ordered as in shallow, followed by any found only in deep. Either argument may
+// be nil.
+func MergeSearchResults(shallow *SearchResults, deep *DeepSearchResults) []EnrichedResult {
+	var merged []EnrichedResult
+	index := make(map[string]int)
+	if shallow != nil {
+		for i := range shallow.Results {
+			r := &shallow.Results[i]
+			index[r.Zpid] = len(merged)
+			merged = append(merged, EnrichedResult{Zpid: r.Zpid, Shallow: r})
+		}
+	}
+	if deep != nil {
+		for i := range deep.Results {
+			r := &deep.Results[i]
+			if j, ok := index[r.Zpid]; ok {
+				merged[j].Deep = r
+				continue
+			}
+			index[r.Zpid] = len(merged)
+			merged = append(merged, EnrichedResult{Zpid: r.Zpid, Deep: r})
+		}
+	}
+	return merged
+}
+
+// NewSearchRequest returns a SearchRequest for street with a CityStateZip of
+// the form "Seattle, WA 98109". Empty components are left out, so "Seattle",
+// "WA 98109" and "98109" are also possible.
+func NewSearchRequest(street, city, state, zip string) SearchRequest {
+	var cityState []string
+	for _, s := range []string{city, state} {
+		if s = strings.TrimSpace(s); s != "" {
+			cityState = append(cityState, s)
+		}
+	}
+	cityStateZip := strings.Join(cityState, ", ")
+	if zip = strings.TrimSpace(zip); zip != "" {
+		cityStateZip = strings.TrimSpace(cityStateZip + " " + zip)
+	}
+	return SearchRequest{Address: strings.TrimSpace(street), CityStateZip: cityStateZip}
+}
+
+// checkSearch applies the checks common to the search endpoints: that the
+// address Zillow echoed matches request, and that zpids, the results, are
+// unambiguous if required.
+func (z *zillow) checkSearch(request, echoed SearchRequest, zpids []string) error {
+	if err := z.checkEcho(addressParam, request.Address, echoed.Address); err != nil {
+		return err
+	} else if err := z.checkEcho(cityStateZipParam, request.CityStateZip, echoed.CityStateZip); err != nil {
+		return err
+	}
+	return z.checkSingleResult(zpids)
+}
+
+// checkSingleResult returns an *AmbiguousAddressError if strict single results
+// are required and there is more than one candidate.
+func (z *zillow) checkSingleResult(zpids []string) error {
+	if !z.strictSingleResult || len(zpids) <= 1 {
+		return nil
+	}
+	return &AmbiguousAddressError{Zpids: zpids}
+}
+
+func resultZpids(results []SearchResult) []string {
+	zpids := make([]string, len(results))
+	for i := range results {
+		zpids[i] = results[i].Zpid
+	}
+	return zpids
+}
+
+func deepResultZpids(results []DeepSearchResult) []string {
+	zpids := make([]string, len(results))
+	for i := range results {
+		zpids[i] = results[i].Zpid
+	}
+	return zpids
+}
+
+func minimalResultZpids(results []MinimalResult) []string {
+	zpids := make([]string, len(results))
+	for i := range results {
+		zpids[i] = results[i].Zpid
+	}
+	return zpids
+}
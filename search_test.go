@@ -0,0 +1,96 @@
+package zillow
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestMergeSearchResults(t *testing.T) {
+	var shallow SearchResults
+	decodeFixture(t, searchResultsPath, &shallow)
+	var deep DeepSearchResults
+	decodeFixture(t, "GetDeepSearchResultsMulti", &deep)
+
+	merged := MergeSearchResults(&shallow, &deep)
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 results but got %d", len(merged))
+	}
+	if m := merged[0]; m.Zpid != zpid || m.Shallow != &shallow.Results[0] || m.Deep != &deep.Results[0] {
+		t.Errorf("expected %s to be joined but got %+v", zpid, m)
+	}
+	for i, m := range merged[1:] {
+		if m.Shallow != nil || m.Deep != &deep.Results[i+1] || m.Zpid != deep.Results[i+1].Zpid {
+			t.Errorf("expected unmatched deep result %s but got %+v", deep.Results[i+1].Zpid, m)
+		}
+	}
+
+	if merged := MergeSearchResults(&shallow, nil); len(merged) != 1 || merged[0].Deep != nil {
+		t.Errorf("expected unmatched shallow result but got %+v", merged)
+	}
+}
+
+func TestNewSearchRequest(t *testing.T) {
+	for _, c := range []struct {
+		city, state, zip string
+		expected         string
+	}{
+		{"Seattle", "WA", "98109", "Seattle, WA 98109"},
+		{"Seattle", "WA", "", "Seattle, WA"},
+		{"", "", "98109", "98109"},
+		{"", "WA", "98109", "WA 98109"},
+		{"Seattle", "", "98109", "Seattle 98109"},
+		{"Seattle", "", "", "Seattle"},
+		{" Seattle ", " WA", "98109 ", "Seattle, WA 98109"},
+		{"", "", "", ""},
+	} {
+		request := NewSearchRequest(address, c.city, c.state, c.zip)
+		expected := SearchRequest{Address: address, CityStateZip: c.expected}
+		if request != expected {
+			t.Errorf("%q, %q, %q: expected %+v but got %+v", c.city, c.state, c.zip, expected, request)
+		}
+	}
+}
+
+func TestWithStrictSingleResult(t *testing.T) {
+	request := SearchRequest{Address: address, CityStateZip: citystatezip}
+	for _, c := range []struct {
+		path, fixture string
+		strict        bool
+		zpids         []string
+	}{
+		{searchResultsPath, searchResultsPath, true, nil},
+		{searchResultsPath, "GetSearchResultsMulti", false, nil},
+		{searchResultsPath, "GetSearchResultsMulti", true, []string{"48749425", "48749430"}},
+		{deepSearchPath, deepSearchPath, true, nil},
+		{deepSearchPath, "GetDeepSearchResultsMulti", false, nil},
+		{deepSearchPath, "GetDeepSearchResultsMulti", true, []string{"48749425", "48749430", "48749445"}},
+	} {
+		var opts []Option
+		if c.strict {
+			opts = append(opts, WithStrictSingleResult())
+		}
+		server, zillow := testFixture(t, c.path, c.fixture, func(url.Values) {}, opts...)
+		var err error
+		if c.path == searchResultsPath {
+			_, err = zillow.GetSearchResults(request)
+		} else {
+			_, err = zillow.GetDeepSearchResults(request)
+		}
+		server.Close()
+
+		if c.zpids == nil {
+			if err != nil {
+				t.Errorf("%s strict=%t: unexpected error %v", c.fixture, c.strict, err)
+			}
+			continue
+		}
+		var ambiguous *AmbiguousAddressError
+		if !errors.Is(err, ErrAmbiguousAddress) || !errors.As(err, &ambiguous) {
+			t.Errorf("%s: expected ErrAmbiguousAddress but got %v", c.fixture, err)
+		} else if !reflect.DeepEqual(ambiguous.Zpids, c.zpids) {
+			t.Errorf("%s: expected candidates %v but got %v", c.fixture, c.zpids, ambiguous.Zpids)
+		}
+	}
+}
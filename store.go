@@ -0,0 +1,79 @@
+package zillow
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// WithResponseStore saves the raw body of every successfully decoded response
+// to dir, as <endpoint>-<hash>.xml, where the hash identifies the request along
+// with the client's defaults, but not the zws-id. Logically identical
+// requests, such as addresses differing only in case or punctuation, share a
+// file, which later calls overwrite. When the response was fetched, as needed
+// for RateSummary.AsOf, is saved alongside in <endpoint>-<hash>.time. A failure
+// to save doesn't fail the call, but is reported as CallStats.StoreErr.
+func WithResponseStore(dir string) Option {
+	return func(z *zillow) {
+		z.storeDir = dir
+	}
+}
+
+// WithReplayFrom serves responses from files saved by WithResponseStore in
+// dir instead of calling Zillow, so no key or network is needed. Calls with no
+// saved response fail.
+func WithReplayFrom(dir string) Option {
+	return func(z *zillow) {
+		z.replayDir = dir
+	}
+}
+
+// store saves body, and fetched in the matching time file.
+func (z *zillow) store(path string, request Request, body []byte, fetched time.Time) error {
+	if err := os.MkdirAll(z.storeDir, 0755); err != nil {
+		return err
+	}
+	name := filepath.Join(z.storeDir, z.storeFile(path, request))
+	if err := ioutil.WriteFile(name, body, 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(timeFile(name), []byte(fetched.Format(time.RFC3339Nano)), 0644)
+}
+
+// replay returns a saved body and when it was fetched, or the zero time if
+// that wasn't saved.
+func (z *zillow) replay(path string, request Request) ([]byte, time.Time, error) {
+	name := filepath.Join(z.replayDir, z.storeFile(path, request))
+	body, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) {
+		return nil, time.Time{}, fmt.Errorf("zillow: no saved %s response for %s", path, z.effective(request).Encode().Encode())
+	} else if err != nil {
+		return nil, time.Time{}, err
+	}
+	b, err := ioutil.ReadFile(timeFile(name))
+	if os.IsNotExist(err) {
+		return body, time.Time{}, nil
+	} else if err != nil {
+		return nil, time.Time{}, err
+	}
+	fetched, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(b)))
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("zillow: invalid fetch time saved for %s: %w", name, err)
+	}
+	return body, fetched, nil
+}
+
+// timeFile names the file holding the fetch time of the response saved as
+// name.
+func timeFile(name string) string {
+	return strings.TrimSuffix(name, ".xml") + ".time"
+}
+
+// storeFile names the file holding the response of path for request, so that
+// requests with the same cacheKey share it.
+func (z *zillow) storeFile(path string, request Request) string {
+	return path + "-" + cacheKey(path, z.effective(request))[:16] + ".xml"
+}
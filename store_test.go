@@ -0,0 +1,149 @@
+package zillow
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zillow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/"+strings.TrimSuffix(path.Base(r.URL.Path), ".htm")+".xml")
+	}))
+	defer server.Close()
+	now := time.Now()
+	clock := WithClock(func() time.Time { return now })
+	record := NewExt(testZwsId, server.URL, WithResponseStore(dir), clock)
+
+	offline, replay := unreachable(t)
+	defer offline.Close()
+	// Replay doesn't need the key used to record.
+	WithReplayFrom(dir)(replay.(*zillow))
+	clock(replay.(*zillow))
+	replay.(*zillow).zwsId = ""
+
+	for _, e := range endpoints {
+		recorded, err := e.call(record)
+		if err != nil {
+			t.Fatalf("%s: record: %v", e.path, err)
+		}
+		replayed, err := e.call(replay)
+		if err != nil {
+			t.Fatalf("%s: replay: %v", e.path, err)
+		}
+		if !reflect.DeepEqual(recorded, replayed) {
+			t.Errorf("%s: expected:\n %s\n\n but got:\n %s", e.path, prettyJSON(t, recorded), prettyJSON(t, replayed))
+		}
+	}
+
+	if _, err := replay.GetZestimate(ZestimateRequest{Zpid: "1"}); err == nil {
+		t.Error("expected error replaying an unrecorded call")
+	}
+}
+
+func TestReplayEquivalentRequest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zillow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	server, record := testFixture(t, searchResultsPath, searchResultsPath, func(url.Values) {}, WithResponseStore(dir), WithDefaultRentZestimate(true))
+	defer server.Close()
+	recorded, err := record.GetSearchResults(SearchRequest{Address: address, CityStateZip: citystatezip})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offline, replay := unreachable(t)
+	defer offline.Close()
+	WithReplayFrom(dir)(replay.(*zillow))
+	// A different spelling of the address, asking for the defaulted rent data
+	// explicitly.
+	replayed, err := replay.GetSearchResults(SearchRequest{Address: " 2114  bigelow AVE. ", CityStateZip: "seattle,  wa", Rentzestimate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("expected:\n %s\n\n but got:\n %s", prettyJSON(t, recorded), prettyJSON(t, replayed))
+	}
+
+	if _, err := replay.GetSearchResults(SearchRequest{Address: address, CityStateZip: citystatezip}); err == nil {
+		t.Error("expected error replaying without the rent data recorded")
+	}
+}
+
+func TestReplayRateSummaryAsOf(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zillow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	recordedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	server, record := testFixture(t, rateSummaryPath, rateSummaryPath, func(url.Values) {}, WithResponseStore(dir), WithClock(func() time.Time { return recordedAt }))
+	defer server.Close()
+	if _, err := record.GetRateSummary(RateSummaryRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Copying the recording doesn't preserve modification times.
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if err := os.Chtimes(filepath.Join(dir, f.Name()), time.Now(), time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	offline, replay := unreachable(t)
+	defer offline.Close()
+	WithReplayFrom(dir)(replay.(*zillow))
+	WithClock(func() time.Time { return recordedAt.AddDate(1, 0, 0) })(replay.(*zillow))
+	result, err := replay.GetRateSummary(RateSummaryRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asOf, err := result.AsOfTime(); err != nil {
+		t.Fatal(err)
+	} else if !asOf.Equal(recordedAt) {
+		t.Errorf("expected AsOf %s from the recording but got %s", recordedAt, asOf)
+	}
+}
+
+func TestResponseStoreFailure(t *testing.T) {
+	f, err := ioutil.TempFile("", "zillow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	// The store dir is a file, so saving fails.
+	var stats []CallStats
+	server, client := testFixture(t, zestimatePath, zestimatePath, func(url.Values) {},
+		WithResponseStore(f.Name()), WithObserver(func(s CallStats) { stats = append(stats, s) }))
+	defer server.Close()
+	if _, err := client.GetZestimate(ZestimateRequest{Zpid: zpid}); err != nil {
+		t.Fatalf("expected the call to succeed but got %v", err)
+	}
+	if len(stats) != 1 || stats[0].Err != nil || stats[0].StoreErr == nil {
+		t.Errorf("expected a store error to be observed but got %+v", stats)
+	}
+}
@@ -0,0 +1,139 @@
+package zillow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Chart dimensions accepted by GetChart and GetRegionChart, in pixels.
+const (
+	minChartWidth  = 200
+	maxChartWidth  = 600
+	minChartHeight = 100
+	maxChartHeight = 300
+)
+
+func validateChartSize(width, height int) error {
+	if width < minChartWidth || width > maxChartWidth {
+		return fmt.Errorf("zillow: chart width %d out of range [%d, %d]", width, minChartWidth, maxChartWidth)
+	}
+	if height < minChartHeight || height > maxChartHeight {
+		return fmt.Errorf("zillow: chart height %d out of range [%d, %d]", height, minChartHeight, maxChartHeight)
+	}
+	return nil
+}
+
+// validateZpid checks that zpid, which is required, is all digits.
+func validateZpid(zpid string) error {
+	if zpid == "" {
+		return errors.New("zillow: request missing Zpid")
+	}
+	for _, c := range zpid {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("zillow: zpid %q is not numeric", zpid)
+		}
+	}
+	return nil
+}
+
+func (r ZestimateRequest) validate() error {
+	return validateZpid(r.Zpid)
+}
+
+func (r ChartRequest) validate() error {
+	if err := validateZpid(r.Zpid); err != nil {
+		return err
+	}
+	return validateChartSize(r.Width, r.Height)
+}
+
+func (r CompsRequest) validate() error {
+	return validateZpid(r.Zpid)
+}
+
+func (r UpdatedPropertyDetailsRequest) validate() error {
+	return validateZpid(r.Zpid)
+}
+
+func (r RegionChartRequest) validate() error {
+	return validateChartSize(r.Width, r.Height)
+}
+
+// validate checks the fields GetSearchResults and GetDeepSearchResults require.
+func (r SearchRequest) validate() error {
+	if strings.TrimSpace(r.Address) == "" {
+		return errors.New("zillow: search request missing Address")
+	}
+	if strings.TrimSpace(r.CityStateZip) == "" {
+		return errors.New("zillow: search request missing CityStateZip")
+	}
+	return nil
+}
+
+// Validate checks that r is a coherent request: either AnnualIncome, with
+// MonthlyDebts if any, or MonthlyPayment, along with a Rate, Schedule and
+// TermInMonths. CalculateAffordability calls it before making a request.
+func (r AffordabilityRequest) Validate() error {
+	if err := validateZip(r.Zip); err != nil {
+		return err
+	}
+	for _, p := range []struct {
+		name    string
+		percent Percent
+	}{
+		{"DebtToIncome", r.DebtToIncome},
+		{"IncomeTax", r.IncomeTax},
+		{"PropertyTax", r.PropertyTax},
+	} {
+		if !p.percent.Valid() {
+			return fmt.Errorf("zillow: %s %v%% out of range [0, 100]", p.name, p.percent)
+		}
+	}
+	if r.AnnualIncome == 0 && r.MonthlyPayment == 0 {
+		return errors.New("zillow: either AnnualIncome or MonthlyPayment is required")
+	} else if r.MonthlyDebts != 0 && r.AnnualIncome == 0 {
+		return errors.New("zillow: AnnualIncome is required with MonthlyDebts")
+	} else if r.Rate <= 0 {
+		return errors.New("zillow: Rate is required")
+	} else if strings.TrimSpace(r.Schedule) == "" {
+		return errors.New("zillow: Schedule is required")
+	} else if r.TermInMonths <= 0 {
+		return errors.New("zillow: TermInMonths is required")
+	}
+	return nil
+}
+
+// validZip reports whether zip is a 5 digit ZIP code, optionally followed by
+// a hyphen and 4 more digits.
+func validZip(zip string) bool {
+	if len(zip) != 5 && len(zip) != 10 {
+		return false
+	}
+	for i, c := range zip {
+		if i == 5 {
+			if c != '-' {
+				return false
+			}
+		} else if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateZip checks zip, which is optional.
+func validateZip(zip string) error {
+	if zip != "" && !validZip(zip) {
+		return fmt.Errorf("zillow: zip %q is not a 5 digit or ZIP+4 code", zip)
+	}
+	return nil
+}
+
+func (r MonthlyPaymentsRequest) validate() error {
+	return validateZip(r.Zip)
+}
+
+func (r MonthlyPaymentsAdvancedRequest) validate() error {
+	return validateZip(r.Zip)
+}
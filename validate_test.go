@@ -0,0 +1,192 @@
+package zillow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// unreachable returns a client whose server fails the test if called.
+func unreachable(t *testing.T) (*httptest.Server, Zillow) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s", r.URL)
+	}))
+	return server, &zillow{zwsId: testZwsId, url: server.URL}
+}
+
+func TestChartSizeValidation(t *testing.T) {
+	server, zillow := unreachable(t)
+	defer server.Close()
+
+	for _, size := range []struct{ width, height int }{
+		{0, 0},
+		{0, height},
+		{width, 0},
+		{-300, height},
+		{width, -150},
+		{maxChartWidth + 1, height},
+		{width, maxChartHeight + 1},
+	} {
+		if _, err := zillow.GetChart(ChartRequest{Zpid: zpid, Width: size.width, Height: size.height}); err == nil {
+			t.Errorf("GetChart: expected error for %dx%d", size.width, size.height)
+		}
+		if _, err := zillow.GetRegionChart(RegionChartRequest{City: city, State: state, Width: size.width, Height: size.height}); err == nil {
+			t.Errorf("GetRegionChart: expected error for %dx%d", size.width, size.height)
+		}
+	}
+}
+
+func TestSearchRequestValidation(t *testing.T) {
+	server, zillow := unreachable(t)
+	defer server.Close()
+
+	for _, c := range []struct {
+		request SearchRequest
+		missing string
+	}{
+		{SearchRequest{CityStateZip: citystatezip}, "Address"},
+		{SearchRequest{Address: "  ", CityStateZip: citystatezip}, "Address"},
+		{SearchRequest{Address: address}, "CityStateZip"},
+		{SearchRequest{}, "Address"},
+	} {
+		if _, err := zillow.GetSearchResults(c.request); err == nil || !strings.Contains(err.Error(), c.missing) {
+			t.Errorf("GetSearchResults(%#v): expected error naming %s but got %v", c.request, c.missing, err)
+		}
+		if _, err := zillow.GetDeepSearchResults(c.request); err == nil || !strings.Contains(err.Error(), c.missing) {
+			t.Errorf("GetDeepSearchResults(%#v): expected error naming %s but got %v", c.request, c.missing, err)
+		}
+	}
+
+	if err := (SearchRequest{Address: address, CityStateZip: citystatezip}).validate(); err != nil {
+		t.Errorf("expected valid request but got %v", err)
+	}
+}
+
+func TestAffordabilityPercentValidation(t *testing.T) {
+	server, zillow := unreachable(t)
+	defer server.Close()
+
+	for _, c := range []struct {
+		request AffordabilityRequest
+		field   string
+	}{
+		{AffordabilityRequest{DebtToIncome: 3600}, "DebtToIncome"},
+		{AffordabilityRequest{IncomeTax: -30}, "IncomeTax"},
+		{AffordabilityRequest{PropertyTax: 120}, "PropertyTax"},
+	} {
+		if _, err := zillow.CalculateAffordability(c.request); err == nil || !strings.Contains(err.Error(), c.field) {
+			t.Errorf("expected error naming %s but got %v", c.field, err)
+		}
+	}
+
+	valid := AffordabilityRequest{MonthlyPayment: monthlyPayment, Rate: rate, Schedule: schedule, TermInMonths: termInMonths, DebtToIncome: debtToIncome, IncomeTax: incomeTax, PropertyTax: 1.2}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected valid request but got %v", err)
+	}
+}
+
+func TestAffordabilityRequestValidate(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		request AffordabilityRequest
+		missing string
+	}{
+		{"dti", AffordabilityRequest{AnnualIncome: annualIncome, MonthlyDebts: monthlyDebts, Rate: rate, Schedule: schedule, TermInMonths: termInMonths}, ""},
+		{"payment", AffordabilityRequest{MonthlyPayment: monthlyPayment, Rate: rate, Schedule: schedule, TermInMonths: termInMonths}, ""},
+		{"both", AffordabilityRequest{AnnualIncome: annualIncome, MonthlyDebts: monthlyDebts, MonthlyPayment: monthlyPayment, Rate: rate, Schedule: schedule, TermInMonths: termInMonths}, ""},
+		{"neither", AffordabilityRequest{Rate: rate, Schedule: schedule, TermInMonths: termInMonths}, "MonthlyPayment"},
+		{"income with zero debts", AffordabilityRequest{AnnualIncome: annualIncome, Rate: rate, Schedule: schedule, TermInMonths: termInMonths}, ""},
+		{"debts without income", AffordabilityRequest{MonthlyDebts: monthlyDebts, MonthlyPayment: monthlyPayment, Rate: rate, Schedule: schedule, TermInMonths: termInMonths}, "AnnualIncome"},
+		{"no rate", AffordabilityRequest{MonthlyPayment: monthlyPayment, Schedule: schedule, TermInMonths: termInMonths}, "Rate"},
+		{"no schedule", AffordabilityRequest{MonthlyPayment: monthlyPayment, Rate: rate, TermInMonths: termInMonths}, "Schedule"},
+		{"no term", AffordabilityRequest{MonthlyPayment: monthlyPayment, Rate: rate, Schedule: schedule}, "TermInMonths"},
+	} {
+		err := c.request.Validate()
+		if c.missing == "" {
+			if err != nil {
+				t.Errorf("%s: expected valid request but got %v", c.name, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), c.missing) {
+			t.Errorf("%s: expected error naming %s but got %v", c.name, c.missing, err)
+		}
+	}
+
+	server, zillow := unreachable(t)
+	defer server.Close()
+	if _, err := zillow.CalculateAffordability(AffordabilityRequest{AnnualIncome: annualIncome}); err == nil {
+		t.Error("expected CalculateAffordability to validate the request")
+	}
+}
+
+func TestValidZip(t *testing.T) {
+	for zip, expected := range map[string]bool{
+		"98104":      true,
+		"98104-1234": true,
+		"":           false,
+		"9810":       false,
+		"981044":     false,
+		"98104-123":  false,
+		"98104 1234": false,
+		"9810a":      false,
+		"98104-12a4": false,
+	} {
+		if actual := validZip(zip); actual != expected {
+			t.Errorf("%q: expected %t but got %t", zip, expected, actual)
+		}
+	}
+}
+
+func TestZipValidation(t *testing.T) {
+	server, zillow := unreachable(t)
+	defer server.Close()
+
+	const bad = "981"
+	if _, err := zillow.GetMonthlyPayments(MonthlyPaymentsRequest{Price: price, Zip: bad}); err == nil || !strings.Contains(err.Error(), bad) {
+		t.Errorf("GetMonthlyPayments: expected error naming %q but got %v", bad, err)
+	}
+	if _, err := zillow.CalculateMonthlyPaymentsAdvanced(MonthlyPaymentsAdvancedRequest{Price: price, Zip: bad}); err == nil || !strings.Contains(err.Error(), bad) {
+		t.Errorf("CalculateMonthlyPaymentsAdvanced: expected error naming %q but got %v", bad, err)
+	}
+	if _, err := zillow.CalculateAffordability(AffordabilityRequest{AnnualIncome: annualIncome, Zip: bad}); err == nil || !strings.Contains(err.Error(), bad) {
+		t.Errorf("CalculateAffordability: expected error naming %q but got %v", bad, err)
+	}
+}
+
+func TestZpidValidation(t *testing.T) {
+	server, zillow := unreachable(t)
+	defer server.Close()
+
+	for _, id := range []string{"", "abc", "4874-9425", " 48749425"} {
+		for name, call := range map[string]func() error{
+			"GetZestimate": func() error {
+				_, err := zillow.GetZestimate(ZestimateRequest{Zpid: id})
+				return err
+			},
+			"GetChart": func() error {
+				_, err := zillow.GetChart(ChartRequest{Zpid: id, Width: width, Height: height})
+				return err
+			},
+			"GetComps": func() error {
+				_, err := zillow.GetComps(CompsRequest{Zpid: id, Count: count})
+				return err
+			},
+			"GetDeepComps": func() error {
+				_, err := zillow.GetDeepComps(CompsRequest{Zpid: id, Count: count})
+				return err
+			},
+			"GetUpdatedPropertyDetails": func() error {
+				_, err := zillow.GetUpdatedPropertyDetails(UpdatedPropertyDetailsRequest{Zpid: id})
+				return err
+			},
+		} {
+			if err := call(); err == nil || !strings.Contains(strings.ToLower(err.Error()), "zpid") {
+				t.Errorf("%s(%q): expected zpid error but got %v", name, id, err)
+			}
+		}
+	}
+
+	if err := validateZpid(zpid); err != nil {
+		t.Errorf("expected valid zpid but got %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package zillow
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RentZestimateStatus tells whether a rent Zestimate was requested and
+// returned.
+type RentZestimateStatus string
+
+const (
+	// RentZestimateNotRequested means rent data wasn't asked for.
+	RentZestimateNotRequested RentZestimateStatus = "not_requested"
+	// RentZestimateUnavailable means rent data was asked for, but Zillow has
+	// none for the property.
+	RentZestimateUnavailable RentZestimateStatus = "unavailable"
+	// RentZestimatePresent means the rent Zestimate was returned.
+	RentZestimatePresent RentZestimateStatus = "present"
+)
+
+func rentZestimateStatus(requested bool, rent *Zestimate) RentZestimateStatus {
+	if rent != nil {
+		return RentZestimatePresent
+	} else if requested {
+		return RentZestimateUnavailable
+	}
+	return RentZestimateNotRequested
+}
+
+func (z *zillow) GetRentZestimate(ctx context.Context, zpid string) (*Zestimate, error) {
+	result, err := z.zestimate(ctx, ZestimateRequest{Zpid: zpid, Rentzestimate: true})
+	if err != nil {
+		return nil, err
+	}
+	// Accounts without rent data are reported via the message.
+	if err := result.Message.Err(); err != nil {
+		return nil, err
+	}
+	if result.RentZestimate == nil {
+		return nil, ErrNoRentZestimate
+	}
+	return result.RentZestimate, nil
+}
+
+func (z *zillow) PropertyExists(ctx context.Context, zpid string) (bool, error) {
+	result, err := z.zestimate(ctx, ZestimateRequest{Zpid: zpid})
+	if err != nil {
+		return false, err
+	}
+	if err := result.Message.Err(); errors.Is(err, ErrNoMatch) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (z *zillow) GetCompsForZestimate(ctx context.Context, result *ZestimateResult, count int) (*DeepCompsResult, error) {
+	zpid := result.Zpid
+	if zpid == "" {
+		zpid = result.Request.Zpid
+	}
+	if zpid == "" {
+		return nil, errors.New("zillow: zestimate result has no zpid")
+	}
+	return z.deepComps(ctx, CompsRequest{Zpid: zpid, Count: count})
+}
+
+func (z *zillow) ValueProperty(ctx context.Context, request SearchRequest, count int) (*DeepSearchResult, *DeepCompsResult, error) {
+	search, err := z.deepSearchResults(ctx, request)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := search.Message.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(search.Results) == 0 {
+		return nil, nil, ErrNoMatch
+	}
+	// Zillow lists the best match first.
+	property := &search.Results[0]
+	comps, err := z.deepComps(ctx, CompsRequest{Zpid: property.Zpid, Count: count})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := comps.Message.Err(); err != nil {
+		return nil, nil, err
+	}
+	return property, comps, nil
+}
+
+func (z *zillow) GetRegionChartForZestimate(ctx context.Context, result *ZestimateResult, opts RegionChartOptions) (*RegionChartResult, error) {
+	if result.Address.City == "" || result.Address.State == "" {
+		return nil, errors.New("zillow: zestimate result has no city and state")
+	}
+	request := RegionChartRequest{
+		City:          result.Address.City,
+		State:         result.Address.State,
+		UnitType:      opts.UnitType,
+		Width:         opts.Width,
+		Height:        opts.Height,
+		ChartDuration: opts.ChartDuration,
+	}
+	for _, r := range result.LocalRealEstate {
+		if r.Type == "neighborhood" {
+			request.Neighborhood = r.Name
+			break
+		}
+	}
+	return z.regionChart(ctx, request)
+}
+
+// ZestimateKind tells whether a Zestimate values a sale or a rent.
+type ZestimateKind int
+
+const (
+	// SaleZestimate is a home value, decoded from <zestimate>.
+	SaleZestimate ZestimateKind = iota
+	// RentZestimate is a monthly rent, decoded from <rentzestimate>.
+	RentZestimate
+)
+
+// ZestimateStatus is the confidence Zillow marks a Zestimate with. It is empty
+// for a regular Zestimate, which Zillow leaves unmarked.
+type ZestimateStatus string
+
+// ZestimatePreliminary is a low confidence Zestimate, e.g. for a property with
+// few facts or sales nearby.
+const ZestimatePreliminary ZestimateStatus = "preliminary"
+
+// IsPreliminary reports whether Zillow marked z as preliminary. A Zestimate
+// without a Status is not.
+func (z Zestimate) IsPreliminary() bool {
+	return strings.EqualFold(string(z.Status), string(ZestimatePreliminary))
+}
+
+// UnmarshalXML decodes z as usual, and sets Kind from the element name.
+func (z *Zestimate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type zestimate Zestimate
+	if err := d.DecodeElement((*zestimate)(z), &start); err != nil {
+		return err
+	}
+	z.Kind = SaleZestimate
+	if start.Name.Local == "rentzestimate" {
+		z.Kind = RentZestimate
+	}
+	return nil
+}
+
+// String formats the amount, e.g. "$1,219,500", with a "/mo" suffix for a
+// rent Zestimate, e.g. "$3,800/mo". Amounts in currencies other than USD
+// are followed by the currency code instead of preceded by "$".
+func (z Zestimate) String() string {
+	s := formatAmount(z.Amount)
+	if z.Kind == RentZestimate {
+		s += "/mo"
+	}
+	return s
+}
+
+func formatAmount(v Value) string {
+	n := v.Value
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	digits := strconv.Itoa(n)
+	var grouped []byte
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped = append(grouped, ',')
+		}
+		grouped = append(grouped, digits[i])
+	}
+	if v.Currency == "" || v.Currency == "USD" {
+		return sign + "$" + string(grouped)
+	}
+	return sign + string(grouped) + " " + v.Currency
+}
+
+// UpdatedTime parses LastUpdated. A Zestimate which was never updated fails
+// with ErrUnknownDate.
+func (z Zestimate) UpdatedTime() (time.Time, error) {
+	return parseDate(z.LastUpdated)
+}
+
+// RangeWidthPercent returns the width of the valuation range as a percentage
+// of the amount, (High-Low)/Amount*100. A wide range means low confidence. It
+// returns 0 if the amount is zero.
+func (z Zestimate) RangeWidthPercent() float64 {
+	if z.Amount.Value == 0 {
+		return 0
+	}
+	return float64(z.High.Value-z.Low.Value) / float64(z.Amount.Value) * 100
+}
+
+// IsWideRange reports whether RangeWidthPercent exceeds thresholdPct. A
+// Zestimate without an amount is never wide.
+func (z Zestimate) IsWideRange(thresholdPct float64) bool {
+	return z.Amount.Value != 0 && z.RangeWidthPercent() > thresholdPct
+}
+
+// ResolvedParams returns the query parameters of Url, which are the chart
+// parameters Zillow resolved the request to, such as chartDuration and width.
+func (r *ChartResult) ResolvedParams() (url.Values, error) {
+	return queryParams(r.Url)
+}
+
+func queryParams(rawURL string) (url.Values, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return url.ParseQuery(u.RawQuery)
+}
+
+// RegionChartFlags are the display flags of a region chart URL.
+type RegionChartFlags struct {
+	// ShowCity is set when the chart includes the city, for context.
+	ShowCity bool
+	// ShowPercent is set when the chart plots percent change rather than
+	// dollars, per the requested UnitType.
+	ShowPercent bool
+}
+
+// Flags parses the showCity and showPercent parameters of Url, so callers can
+// confirm how Zillow resolved the request. Missing parameters are false.
+func (r *RegionChartResult) Flags() (RegionChartFlags, error) {
+	var flags RegionChartFlags
+	params, err := queryParams(r.Url)
+	if err != nil {
+		return flags, err
+	}
+	for name, flag := range map[string]*bool{
+		"showCity":    &flags.ShowCity,
+		"showPercent": &flags.ShowPercent,
+	} {
+		v := params.Get(name)
+		if v == "" {
+			continue
+		}
+		if *flag, err = strconv.ParseBool(v); err != nil {
+			return RegionChartFlags{}, fmt.Errorf("zillow: %s: %w", name, err)
+		}
+	}
+	return flags, nil
+}
@@ -0,0 +1,439 @@
+package zillow
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGetRentZestimate(t *testing.T) {
+	server, zillow := testFixture(t, zestimatePath, "GetZestimateRent", func(values url.Values) {
+		assertOnlyParam(t, values, zpidParam, zpid)
+		assertOnlyParam(t, values, rentzestimateParam, "true")
+	})
+	defer server.Close()
+
+	result, err := zillow.GetRentZestimate(context.Background(), zpid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Zestimate{
+		Amount:      Value{Currency: "USD", Value: 3800, Present: true},
+		LastUpdated: "11/01/2009",
+		ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: 120},
+		Low:         Value{Currency: "USD", Value: 3040, Present: true},
+		High:        Value{Currency: "USD", Value: 4560, Present: true},
+		Kind:        RentZestimate,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("expected:\n %s\n\n but got:\n %s", prettyJSON(t, expected), prettyJSON(t, result))
+	}
+}
+
+func TestGetRentZestimateNotEntitled(t *testing.T) {
+	server, zillow := testFixture(t, zestimatePath, "GetZestimateNotEntitled", func(url.Values) {})
+	defer server.Close()
+
+	_, err := zillow.GetRentZestimate(context.Background(), zpid)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 4 {
+		t.Fatalf("expected code 4 APIError but got %v", err)
+	}
+}
+
+func TestGetRentZestimateMissing(t *testing.T) {
+	server, zillow := testFixtures(t, zestimatePath, func(url.Values) {})
+	defer server.Close()
+
+	if _, err := zillow.GetRentZestimate(context.Background(), zpid); err != ErrNoRentZestimate {
+		t.Fatalf("expected ErrNoRentZestimate but got %v", err)
+	}
+}
+
+func TestPropertyExists(t *testing.T) {
+	for _, c := range []struct {
+		fixture  string
+		expected bool
+	}{
+		{zestimatePath, true},
+		{"GetZestimateNoMatch", false},
+		{"GetZestimateNoMatch508", false},
+	} {
+		server, zillow := testFixture(t, zestimatePath, c.fixture, func(values url.Values) {
+			assertOnlyParam(t, values, zpidParam, zpid)
+		})
+		exists, err := zillow.PropertyExists(context.Background(), zpid)
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists != c.expected {
+			t.Errorf("%s: expected %t but got %t", c.fixture, c.expected, exists)
+		}
+	}
+}
+
+func TestPropertyExistsErrors(t *testing.T) {
+	server, zillow := testFixture(t, zestimatePath, "GetZestimateNotEntitled", func(url.Values) {})
+	defer server.Close()
+	if _, err := zillow.PropertyExists(context.Background(), zpid); err == nil {
+		t.Fatal("expected error for non-match error code")
+	}
+
+	// Transport errors are returned rather than reported as a missing property.
+	server.Close()
+	if _, err := zillow.PropertyExists(context.Background(), zpid); err == nil {
+		t.Fatal("expected error for closed server")
+	}
+}
+
+func TestGetZestimateMyZestimator(t *testing.T) {
+	server, zillow := testFixture(t, zestimatePath, "GetZestimateMyZestimator", func(url.Values) {})
+	defer server.Close()
+
+	result, err := zillow.GetZestimate(ZestimateRequest{Zpid: zpid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Links{
+		XMLName:       xml.Name{Local: "links"},
+		HomeDetails:   "http://www.zillow.com/homedetails/2114-Bigelow-Ave-N-Seattle-WA-98109/48749425_zpid/",
+		GraphsAndData: "http://www.zillow.com/homedetails/charts/48749425_zpid,1year_chartDuration/?cbt=2950402095890968938%7E4%7ECh-lwa20e2Scegkf_Ev1dsQ2hJD7f74f1dovt2o0BMi2IuvfsZN-sg**",
+		MapThisHome:   "http://www.zillow.com/homes/map/48749425_zpid/",
+		MyZestimator:  "http://www.zillow.com/myzestimator/MyZestimatorHomeFactsPage.htm?zprop=48749425",
+		Comparables:   "http://www.zillow.com/homes/comps/48749425_zpid/",
+	}
+	if !reflect.DeepEqual(result.Links, expected) {
+		t.Fatalf("expected:\n %s\n\n but got:\n %s", prettyJSON(t, expected), prettyJSON(t, result.Links))
+	}
+}
+
+func TestGetCompsForZestimate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, zestimatePath+".htm"):
+			http.ServeFile(w, r, "testdata/"+zestimatePath+".xml")
+		case strings.HasSuffix(r.URL.Path, deepCompsPath+".htm"):
+			assertOnlyParam(t, r.URL.Query(), zpidParam, zpid)
+			assertOnlyParam(t, r.URL.Query(), countParam, "3")
+			http.ServeFile(w, r, "testdata/"+deepCompsPath+".xml")
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+	zillow := &zillow{zwsId: testZwsId, url: server.URL}
+
+	zestimate, err := zillow.GetZestimate(ZestimateRequest{Zpid: zpid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	comps, err := zillow.GetCompsForZestimate(context.Background(), zestimate, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comps.Principal.Address != zestimate.Address {
+		t.Fatalf("expected comps for %#v but got %#v", zestimate.Address, comps.Principal.Address)
+	}
+
+	if _, err := zillow.GetCompsForZestimate(context.Background(), &ZestimateResult{}, 3); err == nil {
+		t.Fatal("expected error for result without zpid")
+	}
+}
+
+func TestRentValueChange(t *testing.T) {
+	var result ZestimateResult
+	decodeFixture(t, "GetZestimateRent", &result)
+
+	sale := ValueChange{Duration: 30, Currency: "USD", Value: -41500}
+	rent := ValueChange{Duration: 30, Currency: "USD", Value: 120}
+	if result.Zestimate.ValueChange != sale {
+		t.Errorf("expected sale value change %#v but got %#v", sale, result.Zestimate.ValueChange)
+	}
+	if result.RentZestimate == nil || result.RentZestimate.ValueChange != rent {
+		t.Errorf("expected rent value change %#v but got %#v", rent, result.RentZestimate)
+	}
+}
+
+func TestRentPercentile(t *testing.T) {
+	for fixture, expected := range map[string]string{
+		"GetZestimateRentPercentile": "62",
+		// The sale percentile must not leak into an absent rent percentile.
+		"GetZestimateRent": "",
+	} {
+		var result ZestimateResult
+		decodeFixture(t, fixture, &result)
+		if result.Zestimate.Percentile != "95" {
+			t.Errorf("%s: expected sale percentile 95 but got %q", fixture, result.Zestimate.Percentile)
+		}
+		if result.RentZestimate == nil || result.RentZestimate.Percentile != expected {
+			t.Errorf("%s: expected rent percentile %q but got %+v", fixture, expected, result.RentZestimate)
+		}
+	}
+}
+
+func TestGetRegionChartForZestimate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, zestimatePath+".htm"):
+			http.ServeFile(w, r, "testdata/"+zestimatePath+".xml")
+		case strings.HasSuffix(r.URL.Path, regionChartPath+".htm"):
+			values := r.URL.Query()
+			assertOnlyParam(t, values, cityParam, "Seattle")
+			assertOnlyParam(t, values, stateParam, "WA")
+			assertOnlyParam(t, values, neighborhoodParam, "East Queen Anne")
+			assertOnlyParam(t, values, unitTypeParam, unitType)
+			assertOnlyParam(t, values, widthParam, strconv.Itoa(width))
+			assertOnlyParam(t, values, heightParam, strconv.Itoa(height))
+			http.ServeFile(w, r, "testdata/"+regionChartPath+".xml")
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+	zillow := &zillow{zwsId: testZwsId, url: server.URL}
+
+	zestimate, err := zillow.GetZestimate(ZestimateRequest{Zpid: zpid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chart, err := zillow.GetRegionChartForZestimate(context.Background(), zestimate, RegionChartOptions{UnitType: unitType, Width: width, Height: height})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chart.Url == "" {
+		t.Fatalf("expected chart url but got %#v", chart)
+	}
+
+	if _, err := zillow.GetRegionChartForZestimate(context.Background(), &ZestimateResult{}, RegionChartOptions{Width: width, Height: height}); err == nil {
+		t.Fatal("expected error for result without an address")
+	}
+}
+
+func TestZestimateRangeWidth(t *testing.T) {
+	var result ZestimateResult
+	decodeFixture(t, "GetZestimateRent", &result)
+
+	// (1378035-1024380)/1219500 and (4560-3040)/3800.
+	for _, c := range []struct {
+		name     string
+		z        Zestimate
+		expected float64
+	}{
+		{"sale", result.Zestimate, 29},
+		{"rent", *result.RentZestimate, 40},
+	} {
+		if actual := c.z.RangeWidthPercent(); math.Abs(actual-c.expected) > 1e-9 {
+			t.Errorf("%s: expected %v but got %v", c.name, c.expected, actual)
+		}
+		if !c.z.IsWideRange(25) {
+			t.Errorf("%s: expected wide range at 25%%", c.name)
+		}
+		if c.z.IsWideRange(50) {
+			t.Errorf("%s: expected narrow range at 50%%", c.name)
+		}
+	}
+	if result.Zestimate.IsWideRange(35) == result.RentZestimate.IsWideRange(35) {
+		t.Error("expected only the rent range to be wide at 35%")
+	}
+
+	var zero Zestimate
+	if zero.RangeWidthPercent() != 0 || zero.IsWideRange(0) {
+		t.Error("expected zero amount to have no range width")
+	}
+}
+
+// pathServer serves testdata/<fixtures[path]>.xml for each endpoint path.
+func pathServer(t *testing.T, fixtures map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".htm")
+		fixture, ok := fixtures[path]
+		if !ok {
+			t.Errorf("unexpected path %q", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		switch path {
+		case deepSearchPath:
+			assertOnlyParam(t, r.URL.Query(), addressParam, address)
+		case deepCompsPath:
+			assertOnlyParam(t, r.URL.Query(), zpidParam, zpid)
+			assertOnlyParam(t, r.URL.Query(), countParam, strconv.Itoa(count))
+		}
+		http.ServeFile(w, r, "testdata/"+fixture+".xml")
+	}))
+}
+
+func TestValueProperty(t *testing.T) {
+	server := pathServer(t, map[string]string{deepSearchPath: deepSearchPath, deepCompsPath: deepCompsPath})
+	defer server.Close()
+
+	z := &zillow{zwsId: testZwsId, url: server.URL}
+	property, comps, err := z.ValueProperty(context.Background(), SearchRequest{Address: address, CityStateZip: citystatezip}, count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if property.Zpid != zpid || property.FinishedSqFt != 3470 {
+		t.Errorf("expected the deep facts of %s but got %+v", zpid, property)
+	}
+	if len(comps.Comparables) != 2 || comps.Request.Zpid != zpid {
+		t.Errorf("expected the comps of %s but got %+v", zpid, comps)
+	}
+}
+
+func TestValuePropertyNoMatch(t *testing.T) {
+	server := pathServer(t, map[string]string{deepSearchPath: "GetDeepSearchResultsNoMatch"})
+	defer server.Close()
+
+	z := &zillow{zwsId: testZwsId, url: server.URL}
+	_, _, err := z.ValueProperty(context.Background(), SearchRequest{Address: address, CityStateZip: citystatezip}, count)
+	if !errors.Is(err, ErrNoMatch) {
+		t.Fatalf("expected ErrNoMatch but got %v", err)
+	}
+}
+
+func TestRentZestimateStatus(t *testing.T) {
+	for _, c := range []struct {
+		fixture  string
+		rent     bool
+		expected RentZestimateStatus
+	}{
+		{zestimatePath, false, RentZestimateNotRequested},
+		{"GetZestimateRentUnavailable", true, RentZestimateUnavailable},
+		{"GetZestimateRent", true, RentZestimatePresent},
+	} {
+		server, zillow := testFixture(t, zestimatePath, c.fixture, func(values url.Values) {
+			assertOnlyParam(t, values, rentzestimateParam, strconv.FormatBool(c.rent))
+		})
+		result, err := zillow.GetZestimate(ZestimateRequest{Zpid: zpid, Rentzestimate: c.rent})
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.RentZestimateStatus != c.expected {
+			t.Errorf("%s: expected %q but got %q", c.fixture, c.expected, result.RentZestimateStatus)
+		}
+	}
+
+	// The default applies too.
+	server, zillow := testFixture(t, zestimatePath, "GetZestimateRentUnavailable", func(url.Values) {}, WithDefaultRentZestimate(true))
+	defer server.Close()
+	result, err := zillow.GetZestimate(ZestimateRequest{Zpid: zpid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RentZestimateStatus != RentZestimateUnavailable {
+		t.Errorf("expected %q with a default but got %q", RentZestimateUnavailable, result.RentZestimateStatus)
+	}
+}
+
+func TestChartResultResolvedParams(t *testing.T) {
+	var result ChartResult
+	decodeFixture(t, chartPath, &result)
+	params, err := result.ResolvedParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for param, expected := range map[string]string{
+		"width":         strconv.Itoa(width),
+		"height":        strconv.Itoa(height),
+		"showPercent":   "true",
+		"chartDuration": "1year",
+	} {
+		if actual := params.Get(param); actual != expected {
+			t.Errorf("expected %s %q but got %q", param, expected, actual)
+		}
+	}
+
+	if _, err := (&ChartResult{Url: "http://www.zillow.com/app?width=%zz"}).ResolvedParams(); err == nil {
+		t.Error("expected error for malformed query")
+	}
+}
+
+func TestRegionChartResultFlags(t *testing.T) {
+	var result RegionChartResult
+	decodeFixture(t, regionChartPath, &result)
+	flags, err := result.Flags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (RegionChartFlags{ShowCity: true, ShowPercent: true}); flags != expected {
+		t.Errorf("expected %+v but got %+v", expected, flags)
+	}
+
+	for u, expected := range map[string]RegionChartFlags{
+		"http://www.zillow.com/app?showCity=false&showPercent=true": {ShowPercent: true},
+		"http://www.zillow.com/app?showCity=true":                   {ShowCity: true},
+		"http://www.zillow.com/app":                                 {},
+	} {
+		if actual, err := (&RegionChartResult{Url: u}).Flags(); err != nil || actual != expected {
+			t.Errorf("%s: expected %+v but got %+v, %v", u, expected, actual, err)
+		}
+	}
+	for _, u := range []string{
+		"http://www.zillow.com/app?showCity=%zz",
+		"http://www.zillow.com/app?showPercent=maybe",
+	} {
+		if _, err := (&RegionChartResult{Url: u}).Flags(); err == nil {
+			t.Errorf("%s: expected error", u)
+		}
+	}
+}
+
+func TestZestimateString(t *testing.T) {
+	var result ZestimateResult
+	decodeFixture(t, "GetZestimateRent", &result)
+	if result.Zestimate.Kind != SaleZestimate || result.RentZestimate.Kind != RentZestimate {
+		t.Fatalf("expected sale and rent kinds but got %v and %v", result.Zestimate.Kind, result.RentZestimate.Kind)
+	}
+	for _, c := range []struct {
+		z        Zestimate
+		expected string
+	}{
+		{result.Zestimate, "$1,219,500"},
+		{*result.RentZestimate, "$3,800/mo"},
+		{Zestimate{Amount: Value{Currency: "USD", Value: 999}}, "$999"},
+		{Zestimate{Amount: Value{Currency: "USD", Value: -41500}}, "-$41,500"},
+		{Zestimate{Amount: Value{Currency: "EUR", Value: 250000}, Kind: RentZestimate}, "250,000 EUR/mo"},
+	} {
+		if actual := c.z.String(); actual != c.expected {
+			t.Errorf("expected %q but got %q", c.expected, actual)
+		}
+	}
+}
+
+func TestZestimateStatus(t *testing.T) {
+	var preliminary ZestimateResult
+	decodeFixture(t, "GetZestimatePreliminary", &preliminary)
+	if preliminary.Zestimate.Status != ZestimatePreliminary || !preliminary.Zestimate.IsPreliminary() {
+		t.Errorf("expected preliminary zestimate but got status %q", preliminary.Zestimate.Status)
+	}
+	if preliminary.Zestimate.Amount.Value != 1219500 {
+		t.Errorf("expected the rest of the zestimate to decode but got %+v", preliminary.Zestimate)
+	}
+
+	var unmarked ZestimateResult
+	decodeFixture(t, zestimatePath, &unmarked)
+	if unmarked.Zestimate.Status != "" || unmarked.Zestimate.IsPreliminary() {
+		t.Errorf("expected unmarked zestimate not to be preliminary but got status %q", unmarked.Zestimate.Status)
+	}
+
+	for status, expected := range map[ZestimateStatus]bool{
+		ZestimatePreliminary: true,
+		"Preliminary":        true,
+		"complete":           false,
+		"":                   false,
+	} {
+		if actual := (Zestimate{Status: status}).IsPreliminary(); actual != expected {
+			t.Errorf("%q: expected %t but got %t", status, expected, actual)
+		}
+	}
+}
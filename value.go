@@ -0,0 +1,53 @@
+package zillow
+
+import (
+	"errors"
+	"fmt"
+)
+
+// IsPresent reports whether v was decoded from an element with an amount. A
+// zero Value which isn't present is missing rather than $0.
+func (v Value) IsPresent() bool {
+	return v.Present
+}
+
+// Add returns v+o. An empty currency is compatible with any other. The sum
+// is present if both operands are.
+func (v Value) Add(o Value) (Value, error) {
+	currency, err := v.commonCurrency(o)
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{Currency: currency, Value: v.Value + o.Value, Present: v.Present && o.Present}, nil
+}
+
+// Sub returns v-o. An empty currency is compatible with any other. The
+// difference is present if both operands are.
+func (v Value) Sub(o Value) (Value, error) {
+	currency, err := v.commonCurrency(o)
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{Currency: currency, Value: v.Value - o.Value, Present: v.Present && o.Present}, nil
+}
+
+// Ratio returns v/o. An empty currency is compatible with any other.
+func (v Value) Ratio(o Value) (float64, error) {
+	if _, err := v.commonCurrency(o); err != nil {
+		return 0, err
+	}
+	if o.Value == 0 {
+		return 0, errors.New("zillow: ratio to a zero value")
+	}
+	return float64(v.Value) / float64(o.Value), nil
+}
+
+func (v Value) commonCurrency(o Value) (string, error) {
+	switch {
+	case v.Currency == "":
+		return o.Currency, nil
+	case o.Currency == "" || o.Currency == v.Currency:
+		return v.Currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, v.Currency, o.Currency)
+}
@@ -0,0 +1,87 @@
+package zillow
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+)
+
+func TestValueArithmetic(t *testing.T) {
+	a := Value{Currency: "USD", Value: 1219500}
+	b := Value{Currency: "USD", Value: 1124072}
+
+	if sum, err := a.Add(b); err != nil || sum != (Value{Currency: "USD", Value: 2343572}) {
+		t.Errorf("Add: got %#v, %v", sum, err)
+	}
+	if diff, err := a.Sub(b); err != nil || diff != (Value{Currency: "USD", Value: 95428}) {
+		t.Errorf("Sub: got %#v, %v", diff, err)
+	}
+	if ratio, err := b.Ratio(a); err != nil || ratio != 1124072.0/1219500 {
+		t.Errorf("Ratio: got %v, %v", ratio, err)
+	}
+
+	// An empty currency takes the other's.
+	if sum, err := (Value{Value: 1}).Add(a); err != nil || sum.Currency != "USD" {
+		t.Errorf("Add with empty currency: got %#v, %v", sum, err)
+	}
+	if diff, err := a.Sub(Value{Value: 1}); err != nil || diff != (Value{Currency: "USD", Value: 1219499}) {
+		t.Errorf("Sub with empty currency: got %#v, %v", diff, err)
+	}
+}
+
+func TestValueCurrencyMismatch(t *testing.T) {
+	usd := Value{Currency: "USD", Value: 100}
+	eur := Value{Currency: "EUR", Value: 100}
+	if _, err := usd.Add(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add: expected ErrCurrencyMismatch but got %v", err)
+	}
+	if _, err := usd.Sub(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sub: expected ErrCurrencyMismatch but got %v", err)
+	}
+	if _, err := usd.Ratio(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Ratio: expected ErrCurrencyMismatch but got %v", err)
+	}
+}
+
+func TestValueRatioZero(t *testing.T) {
+	if _, err := (Value{Currency: "USD", Value: 100}).Ratio(Value{Currency: "USD"}); err == nil {
+		t.Error("expected error dividing by zero")
+	}
+}
+
+func TestValuePresent(t *testing.T) {
+	for doc, expected := range map[string]Value{
+		`<r><lastSoldPrice currency="USD">995000</lastSoldPrice></r>`: {Currency: "USD", Value: 995000, Present: true},
+		`<r><lastSoldPrice currency="USD">0</lastSoldPrice></r>`:      {Currency: "USD", Value: 0, Present: true},
+		`<r><lastSoldPrice currency="USD"> 0 </lastSoldPrice></r>`:    {Currency: "USD", Value: 0, Present: true},
+		`<r><lastSoldPrice currency="USD"/></r>`:                      {Currency: "USD"},
+		`<r><lastSoldPrice/></r>`:                                     {},
+		`<r></r>`:                                                     {},
+	} {
+		var r struct {
+			LastSoldPrice Value `xml:"lastSoldPrice"`
+		}
+		if err := xml.Unmarshal([]byte(doc), &r); err != nil {
+			t.Errorf("%s: %v", doc, err)
+		} else if r.LastSoldPrice != expected {
+			t.Errorf("%s: expected %+v but got %+v", doc, expected, r.LastSoldPrice)
+		} else if r.LastSoldPrice.IsPresent() != expected.Present {
+			t.Errorf("%s: expected present %t", doc, expected.Present)
+		}
+	}
+
+	var r struct {
+		LastSoldPrice Value `xml:"lastSoldPrice"`
+	}
+	if err := xml.Unmarshal([]byte(`<r><lastSoldPrice>$0</lastSoldPrice></r>`), &r); err == nil {
+		t.Error("expected error for malformed amount")
+	}
+
+	present := Value{Currency: "USD", Value: 0, Present: true}
+	if sum, _ := present.Add(present); !sum.IsPresent() {
+		t.Error("expected sum of present values to be present")
+	}
+	if sum, _ := present.Add(Value{Currency: "USD"}); sum.IsPresent() {
+		t.Error("expected sum with a missing value not to be present")
+	}
+}
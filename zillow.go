@@ -3,28 +3,120 @@
 package zillow
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+// Zillow is the full client, the union of the interfaces of each service.
 type Zillow interface {
-	// Home Valuation
+	Valuation
+	PropertyDetails
+	Neighborhood
+	Mortgage
+
+	// Capabilities reports which methods the account is entitled to call, keyed
+	// by method name. The result is cached after the first successful call.
+	Capabilities(ctx context.Context) (map[string]bool, error)
+	// Ping makes a single cheap call, a rate summary, to check that the
+	// endpoint is reachable and the ZWSID works. It returns the call's error,
+	// or an *APIError (matching e.g. ErrInvalidZWSID) for a failed message.
+	Ping(ctx context.Context) error
+}
+
+// Valuation is the Home Valuation service.
+type Valuation interface {
 	GetZestimate(ZestimateRequest) (*ZestimateResult, error)
 	GetSearchResults(SearchRequest) (*SearchResults, error)
 	GetChart(ChartRequest) (*ChartResult, error)
+	// GetChartImage calls GetChart and downloads the chart image, returning it
+	// with its content type.
+	GetChartImage(ctx context.Context, request ChartRequest) ([]byte, string, error)
 	GetComps(CompsRequest) (*CompsResult, error)
-
-	// Property Details
+	// GetRentZestimate returns just the rent Zestimate for zpid.
+	GetRentZestimate(ctx context.Context, zpid string) (*Zestimate, error)
+	// PropertyExists reports whether zpid identifies a known property.
+	PropertyExists(ctx context.Context, zpid string) (bool, error)
+	// GetCompsForZestimate calls GetDeepComps for the property of result.
+	GetCompsForZestimate(ctx context.Context, result *ZestimateResult, count int) (*DeepCompsResult, error)
+	// ValueProperty calls GetDeepSearchResults for request and GetDeepComps
+	// for the best match, returning both. A search without a match fails with
+	// an error matching ErrNoMatch.
+	ValueProperty(ctx context.Context, request SearchRequest, count int) (*DeepSearchResult, *DeepCompsResult, error)
+	// GetZestimates calls GetZestimate for each request with at most
+	// concurrency calls in flight. Results and errors are indexed like requests.
+	GetZestimates(ctx context.Context, requests []ZestimateRequest, concurrency int) ([]*ZestimateResult, []error)
+}
+
+// PropertyDetails is the Property Details service.
+type PropertyDetails interface {
 	GetDeepComps(CompsRequest) (*DeepCompsResult, error)
 	GetDeepSearchResults(SearchRequest) (*DeepSearchResults, error)
 	GetUpdatedPropertyDetails(request UpdatedPropertyDetailsRequest) (*UpdatedPropertyDetails, error)
-
-	// Neighborhood Data
+	// GetDeepSearches calls GetDeepSearchResults for each request with at most
+	// concurrency calls in flight. Results and errors are indexed like requests.
+	GetDeepSearches(ctx context.Context, requests []SearchRequest, concurrency int) ([]*DeepSearchResults, []error)
+	// DownloadImages is like Images.Download, but uses the client's options,
+	// such as retries and WithMaxConcurrency.
+	DownloadImages(ctx context.Context, images Images, concurrency int) ([][]byte, []error)
+	// GetDeepSearchResultsStream is like GetDeepSearchResults, but decodes the
+	// response incrementally, calling fn for each result. It stops at the first
+	// error returned by fn and returns it. The body is decoded as it arrives
+	// rather than read in full first. The client's checks, such as
+	// WithStrictSingleResult and WithRequestEchoCheck, apply as for
+	// GetDeepSearchResults; a strict single result is only passed to fn once
+	// the response is known to hold no other. A retried call skips the results
+	// already passed to fn.
+	GetDeepSearchResultsStream(ctx context.Context, request SearchRequest, fn func(DeepSearchResult) error) error
+	// GetDeepSearchResultsPartial is like GetDeepSearchResults, but skips
+	// results which fail to decode, returning a *DecodeError for each.
+	GetDeepSearchResultsPartial(ctx context.Context, request SearchRequest) (*DeepSearchResults, []error, error)
+	// GetDeepSearchResultsMinimal is like GetDeepSearchResults, but only
+	// decodes the zpid, address and Zestimate amount of each result. The rest
+	// is still read, so it saves allocations rather than parsing.
+	GetDeepSearchResultsMinimal(ctx context.Context, request SearchRequest) (*MinimalDeepSearchResults, error)
+}
+
+// Neighborhood is the Neighborhood Data service.
+type Neighborhood interface {
 	GetRegionChildren(RegionChildrenRequest) (*RegionChildren, error)
+	// GetAllRegionChildren is like GetRegionChildren, but adds the children of
+	// each child region, skipping duplicates. Zillow has no paging parameter;
+	// a single response lists every child of one type, so this is for
+	// gathering regions of the next level too, such as the neighborhoods of a
+	// county's cities. The children of each child are requested by its id,
+	// with the State and Country of request, and the child type one level
+	// down from request.ChildType: country, state, county, city, then
+	// neighborhood. Children of a type with no level below, such as
+	// neighborhood or zipcode, aren't expanded, and without a ChildType
+	// Zillow picks the type at both levels. At most 4 child calls are in
+	// flight, within the client's WithMaxConcurrency limit.
+	GetAllRegionChildren(ctx context.Context, request RegionChildrenRequest) (*RegionChildren, error)
 	GetRegionChart(RegionChartRequest) (*RegionChartResult, error)
-
+	// GetRegionCharts calls GetRegionChart for each request with at most
+	// concurrency calls in flight. Results and errors are indexed like requests.
+	GetRegionCharts(ctx context.Context, requests []RegionChartRequest, concurrency int) ([]*RegionChartResult, []error)
+	// GetRegionChartForZestimate calls GetRegionChart for the city, state and
+	// neighborhood of result.
+	GetRegionChartForZestimate(ctx context.Context, result *ZestimateResult, opts RegionChartOptions) (*RegionChartResult, error)
+	// GetRegionChartImage calls GetRegionChart and downloads the chart image,
+	// returning it with its content type.
+	GetRegionChartImage(ctx context.Context, request RegionChartRequest) ([]byte, string, error)
+}
+
+// Mortgage is the Mortgage Rates and Mortgage Calculators services.
+type Mortgage interface {
 	// Mortgage Rates
 	GetRateSummary(RateSummaryRequest) (*RateSummary, error)
 
@@ -32,17 +124,46 @@ type Zillow interface {
 	GetMonthlyPayments(MonthlyPaymentsRequest) (*MonthlyPayments, error)
 	CalculateMonthlyPaymentsAdvanced(MonthlyPaymentsAdvancedRequest) (*MonthlyPaymentsAdvanced, error)
 	CalculateAffordability(AffordabilityRequest) (*Affordability, error)
+	// CalculateAffordabilitySensitivity calls CalculateAffordability
+	// concurrently for base with its Rate adjusted by each of deltas, which
+	// must be distinct, returning the results in the order of deltas. A failed
+	// call doesn't affect the others: its result has a nil Affordability, and
+	// an error naming the failed rates is returned along with the results.
+	CalculateAffordabilitySensitivity(ctx context.Context, base AffordabilityRequest, deltas []float32) ([]RateAffordability, error)
 }
 
 // New creates a new zillow client.
-func New(zwsId string) Zillow {
-	return NewExt(zwsId, baseUrl)
+func New(zwsId string, opts ...Option) Zillow {
+	return NewExt(zwsId, baseUrl, opts...)
 }
 
 // NewExt creates a new zillow client.
 // It's like New but accepts more options.
-func NewExt(zwsId, baseUrl string) Zillow {
-	return &zillow{zwsId, baseUrl}
+func NewExt(zwsId, baseUrl string, opts ...Option) Zillow {
+	z := &zillow{zwsId: zwsId, url: baseUrl}
+	for _, opt := range opts {
+		opt(z)
+	}
+	return z
+}
+
+// base holds what every result has besides its decoded fields.
+type base struct {
+	responsePresent bool
+
+	// Disclaimer is the attribution Zillow includes in some responses. Zillow's
+	// terms of use require displaying it wherever the data is shown.
+	Disclaimer string `xml:"disclaimer"`
+}
+
+// HasResponse reports whether the result had a non-empty <response> element.
+// Without one, the response fields are zero values rather than real data.
+func (b *base) HasResponse() bool {
+	return b.responsePresent
+}
+
+func (b *base) setResponsePresent(present bool) {
+	b.responsePresent = present
 }
 
 type Message struct {
@@ -63,6 +184,52 @@ type Address struct {
 type Value struct {
 	Currency string `xml:"currency,attr"`
 	Value    int    `xml:",chardata"`
+	// Present is set when decoded from an element with an amount, telling a
+	// real 0 apart from an absent or empty element.
+	Present bool `xml:"-"`
+}
+
+func (v *Value) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var e struct {
+		Currency string `xml:"currency,attr"`
+		Text     string `xml:",chardata"`
+	}
+	if err := dec.DecodeElement(&e, &start); err != nil {
+		return err
+	}
+	*v = Value{Currency: e.Currency}
+	s := strings.TrimSpace(e.Text)
+	if s == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	v.Value, v.Present = n, true
+	return nil
+}
+
+// Decimal is a float64 which tolerates thousands separators ("1,054,000") and
+// empty elements when decoded.
+type Decimal float64
+
+func (d *Decimal) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := dec.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	s = strings.Replace(strings.TrimSpace(s), ",", "", -1)
+	if s == "" {
+		*d = 0
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*d = Decimal(f)
+	return nil
 }
 
 type ValueChange struct {
@@ -72,54 +239,79 @@ type ValueChange struct {
 }
 
 type Zestimate struct {
-	Amount      Value  `xml:"amount"`
-	LastUpdated string `xml:"last-updated"`
-	// TODO(pedge): fix
-	//ValueChange ValueChange `xml:"valueChange"`
-	Low        Value  `xml:"valuationRange>low"`
-	High       Value  `xml:"valuationRange>high"`
+	Amount      Value       `xml:"amount"`
+	LastUpdated string      `xml:"last-updated"`
+	ValueChange ValueChange `xml:"valueChange"`
+	Low         Value       `xml:"valuationRange>low"`
+	High        Value       `xml:"valuationRange>high"`
+	// Percentile is Zillow's percentile of the amount. A rent Zestimate's is
+	// on a scale of rents, independent of the sale Zestimate's, and is often
+	// absent.
 	Percentile string `xml:"percentile"`
+	// Kind is set from the element the Zestimate was decoded from.
+	Kind ZestimateKind `xml:"-"`
+	// Status is empty unless Zillow marks the Zestimate, e.g. as preliminary.
+	// See IsPreliminary.
+	Status ZestimateStatus `xml:"status,attr"`
 }
 
 type ZestimateRequest struct {
 	Zpid          string `xml:"zpid"`
 	Rentzestimate bool   `xml:"rentzestimate"`
+	// NoRentzestimate opts the request out of a WithDefaultRentZestimate(true)
+	// default. It has no effect when Rentzestimate is set.
+	NoRentzestimate bool `xml:"-"`
 }
 
 type RealEstateRegion struct {
 	XMLName xml.Name `xml:"region"`
 
-	ID                  string  `xml:"id,attr"`
-	Type                string  `xml:"type,attr"`
-	Name                string  `xml:"name,attr"`
-	ZIndex              string  `xml:"zindexValue"`
-	ZIndexOneYearChange float64 `xml:"zindexOneYearChange"`
+	ID     string `xml:"id,attr"`
+	Type   string `xml:"type,attr"`
+	Name   string `xml:"name,attr"`
+	ZIndex string `xml:"zindexValue"`
+	// ZIndexOneYearChange is nil if the response omits it, as deep search
+	// results do.
+	ZIndexOneYearChange *float64 `xml:"zindexOneYearChange"`
 	// Links
 	Overview       string `xml:"links>overview"`
 	ForSaleByOwner string `xml:"links>forSaleByOwner"`
 	ForSale        string `xml:"links>forSale"`
 }
 
+// Links are the property pages returned by GetZestimate, GetSearchResults,
+// GetComps, GetDeepComps and GetDeepSearchResults. Comps links contain a
+// literal <ZWSID> placeholder in place of the partner key; use Resolve to
+// make them usable.
 type Links struct {
 	XMLName xml.Name `xml:"links"`
 
 	HomeDetails   string `xml:"homedetails"`
 	GraphsAndData string `xml:"graphsanddata"`
 	MapThisHome   string `xml:"mapthishome"`
-	MyZestimator  string `xml:"myzestimator"`
-	Comparables   string `xml:"comparables"`
+	// MyZestimator is only present for some properties. GetComps includes it
+	// for comparables, and GetZestimate for properties with owner-edited facts;
+	// the other endpoints usually leave it empty.
+	MyZestimator string `xml:"myzestimator"`
+	Comparables  string `xml:"comparables"`
 }
 
 type ZestimateResult struct {
 	XMLName xml.Name `xml:"zestimate"`
+	base
 
 	Request ZestimateRequest `xml:"request"`
 	Message Message          `xml:"message"`
 
-	Links           Links              `xml:"response>links"`
-	Address         Address            `xml:"response>address"`
-	Zestimate       Zestimate          `xml:"response>zestimate"`
-	LocalRealEstate []RealEstateRegion `xml:"response>localRealEstate>region"`
+	Zpid            string            `xml:"response>zpid"`
+	Links           Links             `xml:"response>links"`
+	Address         Address           `xml:"response>address"`
+	Zestimate       Zestimate         `xml:"response>zestimate"`
+	RentZestimate   *Zestimate        `xml:"response>rentzestimate"`
+	LocalRealEstate RealEstateRegions `xml:"response>localRealEstate>region"`
+	// RentZestimateStatus tells why RentZestimate is nil. It's set by
+	// GetZestimate rather than decoded.
+	RentZestimateStatus RentZestimateStatus `xml:"-"`
 
 	// Regions
 	ZipcodeID string `xml:"response>regions>zipcode-id"`
@@ -132,10 +324,14 @@ type SearchRequest struct {
 	Address       string `xml:"address"`
 	CityStateZip  string `xml:"citystatezip"`
 	Rentzestimate bool   `xml:"rentzestimate"`
+	// NoRentzestimate opts the request out of a WithDefaultRentZestimate(true)
+	// default. It has no effect when Rentzestimate is set.
+	NoRentzestimate bool `xml:"-"`
 }
 
 type SearchResults struct {
 	XMLName xml.Name `xml:"searchresults"`
+	base
 
 	Request SearchRequest `xml:"request"`
 	Message Message       `xml:"message"`
@@ -148,11 +344,11 @@ type SearchResult struct {
 
 	Zpid string `xml:"zpid"`
 
-	Links           Links              `xml:"links"`
-	Address         Address            `xml:"address"`
-	Zestimate       Zestimate          `xml:"zestimate"`
-	RentZestimate   *Zestimate         `xml:"rentzestimate"`
-	LocalRealEstate []RealEstateRegion `xml:"localRealEstate>region"`
+	Links           Links             `xml:"links"`
+	Address         Address           `xml:"address"`
+	Zestimate       Zestimate         `xml:"zestimate"`
+	RentZestimate   *Zestimate        `xml:"rentzestimate"`
+	LocalRealEstate RealEstateRegions `xml:"localRealEstate>region"`
 }
 
 type ChartRequest struct {
@@ -165,6 +361,7 @@ type ChartRequest struct {
 
 type ChartResult struct {
 	XMLName xml.Name `xml:"chart"`
+	base
 
 	Request ChartRequest `xml:"request"`
 	Message Message      `xml:"message"`
@@ -172,9 +369,14 @@ type ChartResult struct {
 }
 
 type CompsRequest struct {
-	Zpid          string `xml:"zpid"`
-	Count         int    `xml:"count"`
-	Rentzestimate bool   `xml:"rentzestimate"`
+	Zpid string `xml:"zpid"`
+	// Count is the number of comparables, at most 25. A higher Count is
+	// lowered to 25 in the request, unless WithStrictCompsCount is set.
+	Count         int  `xml:"count"`
+	Rentzestimate bool `xml:"rentzestimate"`
+	// NoRentzestimate opts the request out of a WithDefaultRentZestimate(true)
+	// default. It has no effect when Rentzestimate is set.
+	NoRentzestimate bool `xml:"-"`
 }
 
 type Principal struct {
@@ -190,33 +392,49 @@ type Comp struct {
 	Links     Links     `xml:"links"`
 	Address   Address   `xml:"address"`
 	Zestimate Zestimate `xml:"zestimate"`
+	// RentZestimate is only present if rent data was requested and Zillow
+	// has it for the comparable.
+	RentZestimate *Zestimate `xml:"rentzestimate"`
 }
 
 type CompsResult struct {
 	XMLName xml.Name `xml:"comps"`
+	base
 
 	Request CompsRequest `xml:"request"`
 	Message Message      `xml:"message"`
 
-	Principal   Principal `xml:"response>properties>principal"`
-	Comparables []Comp    `xml:"response>properties>comparables>comp"`
+	Principal Principal `xml:"response>properties>principal"`
+	// Comparables is nil if the response has no <comparables> element, e.g.
+	// because the message reports an error, and empty if none were found.
+	Comparables Comps `xml:"response>properties>comparables"`
 }
 
 type DeepPrincipal struct {
-	Zpid             string             `xml:"zpid"`
-	Links            Links              `xml:"links"`
-	Address          Address            `xml:"address"`
-	TaxAssesmentYear int                `xml:"taxAssessmentYear"`
-	TaxAssesment     float64            `xml:"taxAssessment"`
-	YearBuilt        int                `xml:"yearBuilt"`
-	LotSizeSqFt      int                `xml:"lotSizeSqFt"`
-	FinishedSqFt     int                `xml:"finishedSqFt"`
-	Bathrooms        float64            `xml:"bathrooms"`
-	Bedrooms         int                `xml:"bedrooms"`
-	LastSoldDate     string             `xml:"lastSoldDate"`
-	LastSoldPrice    Value              `xml:"lastSoldPrice"`
-	Zestimate        Zestimate          `xml:"zestimate"`
-	LocalRealEstate  []RealEstateRegion `xml:"localRealEstate>region"`
+	Zpid             string            `xml:"zpid"`
+	Links            Links             `xml:"links"`
+	Address          Address           `xml:"address"`
+	TaxAssesmentYear int               `xml:"taxAssessmentYear"`
+	TaxAssesment     Decimal           `xml:"taxAssessment"`
+	YearBuilt        int               `xml:"yearBuilt"`
+	LotSizeSqFt      int               `xml:"lotSizeSqFt"`
+	FinishedSqFt     int               `xml:"finishedSqFt"`
+	Bathrooms        float64           `xml:"bathrooms"`
+	Bedrooms         int               `xml:"bedrooms"`
+	LastSoldDate     string            `xml:"lastSoldDate"`
+	LastSoldPrice    Value             `xml:"lastSoldPrice"`
+	Zestimate        Zestimate         `xml:"zestimate"`
+	LocalRealEstate  RealEstateRegions `xml:"localRealEstate>region"`
+	// Regions is only present in some responses.
+	Regions Regions `xml:"regions"`
+}
+
+// Regions are the Zillow region ids of a property.
+type Regions struct {
+	ZipcodeID string `xml:"zipcode-id"`
+	CityID    string `xml:"city-id"`
+	CountyID  string `xml:"county-id"`
+	StateID   string `xml:"state-id"`
 }
 
 type DeepComp struct {
@@ -225,7 +443,7 @@ type DeepComp struct {
 	Links            Links     `xml:"links"`
 	Address          Address   `xml:"address"`
 	TaxAssesmentYear int       `xml:"taxAssessmentYear"`
-	TaxAssesment     float64   `xml:"taxAssessment"`
+	TaxAssesment     Decimal   `xml:"taxAssessment"`
 	YearBuilt        int       `xml:"yearBuilt"`
 	LotSizeSqFt      int       `xml:"lotSizeSqFt"`
 	FinishedSqFt     int       `xml:"finishedSqFt"`
@@ -234,41 +452,47 @@ type DeepComp struct {
 	LastSoldDate     string    `xml:"lastSoldDate"`
 	LastSoldPrice    Value     `xml:"lastSoldPrice"`
 	Zestimate        Zestimate `xml:"zestimate"`
+	// RentZestimate is like Comp.RentZestimate.
+	RentZestimate *Zestimate `xml:"rentzestimate"`
 }
 
 type DeepCompsResult struct {
 	XMLName xml.Name `xml:"comps"`
+	base
 
 	Request CompsRequest `xml:"request"`
 	Message Message      `xml:"message"`
 
-	Principal   DeepPrincipal `xml:"response>properties>principal"`
-	Comparables []DeepComp    `xml:"response>properties>comparables>comp"`
+	Principal DeepPrincipal `xml:"response>properties>principal"`
+	// Comparables is nil if the response has no <comparables> element, e.g.
+	// because the message reports an error, and empty if none were found.
+	Comparables DeepComps `xml:"response>properties>comparables"`
 }
 
 type DeepSearchResult struct {
 	XMLName xml.Name `xml:"result"`
 
-	Zpid              string             `xml:"zpid"`
-	Links             Links              `xml:"links"`
-	Address           Address            `xml:"address"`
-	FIPSCounty        string             `xml:"FIPScounty"`
-	UseCode           string             `xml:"useCode"`
-	TaxAssessmentYear int                `xml:"taxAssessmentYear"`
-	TaxAssessment     float64            `xml:"taxAssessment"`
-	YearBuilt         int                `xml:"yearBuilt"`
-	LotSizeSqFt       int                `xml:"lotSizeSqFt"`
-	FinishedSqFt      int                `xml:"finishedSqFt"`
-	Bathrooms         float64            `xml:"bathrooms"`
-	Bedrooms          int                `xml:"bedrooms"`
-	LastSoldDate      string             `xml:"lastSoldDate"`
-	LastSoldPrice     Value              `xml:"lastSoldPrice"`
-	Zestimate         Zestimate          `xml:"zestimate"`
-	LocalRealEstate   []RealEstateRegion `xml:"localRealEstate>region"`
+	Zpid              string            `xml:"zpid"`
+	Links             Links             `xml:"links"`
+	Address           Address           `xml:"address"`
+	FIPSCounty        string            `xml:"FIPScounty"`
+	UseCode           string            `xml:"useCode"`
+	TaxAssessmentYear int               `xml:"taxAssessmentYear"`
+	TaxAssessment     Decimal           `xml:"taxAssessment"`
+	YearBuilt         int               `xml:"yearBuilt"`
+	LotSizeSqFt       int               `xml:"lotSizeSqFt"`
+	FinishedSqFt      int               `xml:"finishedSqFt"`
+	Bathrooms         float64           `xml:"bathrooms"`
+	Bedrooms          int               `xml:"bedrooms"`
+	LastSoldDate      string            `xml:"lastSoldDate"`
+	LastSoldPrice     Value             `xml:"lastSoldPrice"`
+	Zestimate         Zestimate         `xml:"zestimate"`
+	LocalRealEstate   RealEstateRegions `xml:"localRealEstate>region"`
 }
 
 type DeepSearchResults struct {
 	XMLName xml.Name `xml:"searchresults"`
+	base
 
 	Request SearchRequest `xml:"request"`
 	Message Message       `xml:"message"`
@@ -287,8 +511,17 @@ type RegionChartRequest struct {
 	ChartDuration string `xml:"chartDuration"`
 }
 
+// RegionChartOptions are the chart settings of a RegionChartRequest.
+type RegionChartOptions struct {
+	UnitType      string
+	Width         int
+	Height        int
+	ChartDuration string
+}
+
 type RegionChartResult struct {
 	XMLName xml.Name `xml:"regionchart"`
+	base
 
 	Request RegionChartRequest `xml:"request"`
 	Message Message            `xml:"message"`
@@ -301,15 +534,55 @@ type UpdatedPropertyDetailsRequest struct {
 	Zpid string `xml:"zpid"`
 }
 
+type Agent struct {
+	Name       string
+	ProfileURL string
+}
+
+type Listing struct {
+	Status      string
+	Type        string
+	MLS         string
+	ExternalURL string
+	LastUpdated string
+}
+
 type Posting struct {
-	Status          string `xml:"status"`
-	AgentName       string `xml:"agentName"`
+	Agent     Agent   `xml:"-"`
+	Listing   Listing `xml:"-"`
+	Brokerage string  `xml:"brokerage"`
+
+	// Deprecated: use Listing.Status.
+	Status string `xml:"status"`
+	// Deprecated: use Agent.Name.
+	AgentName string `xml:"agentName"`
+	// Deprecated: use Agent.ProfileURL.
 	AgentProfileUrl string `xml:"agentProfileUrl"`
-	Brokerage       string `xml:"brokerage"`
-	Type            string `xml:"type"`
+	// Deprecated: use Listing.Type.
+	Type string `xml:"type"`
+	// Deprecated: use Listing.LastUpdated.
 	LastUpdatedDate string `xml:"lastUpdatedDate"`
-	ExternalUrl     string `xml:"externalUrl"`
-	MLS             string `xml:"mls"`
+	// Deprecated: use Listing.ExternalURL.
+	ExternalUrl string `xml:"externalUrl"`
+	// Deprecated: use Listing.MLS.
+	MLS string `xml:"mls"`
+}
+
+// UnmarshalXML decodes the flat posting fields and groups them into Agent and Listing.
+func (p *Posting) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type posting Posting
+	if err := d.DecodeElement((*posting)(p), &start); err != nil {
+		return err
+	}
+	p.Agent = Agent{Name: p.AgentName, ProfileURL: p.AgentProfileUrl}
+	p.Listing = Listing{
+		Status:      p.Status,
+		Type:        p.Type,
+		MLS:         p.MLS,
+		ExternalURL: p.ExternalUrl,
+		LastUpdated: p.LastUpdatedDate,
+	}
+	return nil
 }
 
 type Images struct {
@@ -339,6 +612,7 @@ type EditedFacts struct {
 
 type UpdatedPropertyDetails struct {
 	XMLName xml.Name `xml:"updatedPropertyDetails"`
+	base
 
 	Request UpdatedPropertyDetailsRequest `xml:"request"`
 	Message Message                       `xml:"message"`
@@ -358,9 +632,16 @@ type UpdatedPropertyDetails struct {
 	EditedFacts      EditedFacts `xml:"response>editedFacts"`
 	HomeDescriptions string      `xml:"homeDesription"`
 	Neighborhood     string      `xml:"neighborhood"`
-	SchoolDistrict   string      `xml:"schoolDistrict"`
-	ElementarySchool string      `xml:"elementarySchool"`
-	MiddleSchool     string      `xml:"middleSchool"`
+	Schools          Schools     `xml:"-"`
+	// PriceHistory is in chronological order. See LatestSale.
+	PriceHistory []SaleEvent `xml:"-"`
+
+	// Deprecated: use Schools.District.
+	SchoolDistrict string `xml:"response>schoolDistrict"`
+	// Deprecated: use Schools.Elementary.
+	ElementarySchool string `xml:"response>elementarySchool"`
+	// Deprecated: use Schools.Middle.
+	MiddleSchool string `xml:"response>middleSchool"`
 }
 
 type RegionChildrenRequest struct {
@@ -387,6 +668,7 @@ type Region struct {
 
 type RegionChildren struct {
 	XMLName xml.Name `xml:"regionchildren"`
+	base
 
 	Request RegionChildrenRequest `xml:"request"`
 	Message Message               `xml:"message"`
@@ -401,19 +683,30 @@ type RateSummaryRequest struct {
 }
 
 type Rate struct {
-	LoanType string  `xml:"loanType,attr"`
-	Count    int     `xml:"count,attr"`
-	Value    float64 `xml:",chardata"`
+	LoanType LoanType `xml:"loanType,attr"`
+	Count    int      `xml:"count,attr"`
+	Value    float64  `xml:",chardata"`
 }
 
 type RateSummary struct {
 	XMLName xml.Name `xml:"rateSummary"`
+	base
 
 	Request RateSummaryRequest `xml:"request"`
 	Message Message            `xml:"message"`
 
 	Today    []Rate `xml:"response>today>rate"`
 	LastWeek []Rate `xml:"response>lastWeek>rate"`
+	// AsOf is when the summary was fetched, in RFC 3339 format, since the
+	// response doesn't say when "today" is. A replayed summary keeps the time
+	// it was recorded, or is empty if that wasn't saved.
+	AsOf string `xml:"-"`
+}
+
+func (r *RateSummary) setFetched(t time.Time) {
+	if !t.IsZero() {
+		r.AsOf = t.Format(time.RFC3339)
+	}
 }
 
 type MonthlyPaymentsRequest struct {
@@ -424,14 +717,15 @@ type MonthlyPaymentsRequest struct {
 }
 
 type Payment struct {
-	LoanType                    string  `xml:"loanType,attr"`
-	Rate                        float64 `xml:"rate"`
-	MonthlyPrincipalAndInterest int     `xml:"monthlyPrincipalAndInterest"`
-	MonthlyMortgageInsurance    int     `xml:"monthlyMortgageInsurance"`
+	LoanType                    LoanType `xml:"loanType,attr"`
+	Rate                        float64  `xml:"rate"`
+	MonthlyPrincipalAndInterest int      `xml:"monthlyPrincipalAndInterest"`
+	MonthlyMortgageInsurance    int      `xml:"monthlyMortgageInsurance"`
 }
 
 type MonthlyPayments struct {
 	XMLName xml.Name `xml:"paymentsSummary"`
+	base
 
 	Request MonthlyPaymentsRequest `xml:"request"`
 	Message Message                `xml:"message"`
@@ -440,6 +734,9 @@ type MonthlyPayments struct {
 	DownPayment            int       `xml:"response>downPayment"`
 	MonthlyPropertyTaxes   int       `xml:"response>monthlyPropertyTaxes"`
 	MonthlyHazardInsurance int       `xml:"response>monthlyHazardInsurance"`
+	// EstimatedClosingCosts is set by EstimateClosingCosts rather than
+	// decoded; Zillow doesn't estimate closing costs.
+	EstimatedClosingCosts int `xml:"-"`
 }
 
 type MonthlyPaymentsAdvancedRequest struct {
@@ -471,6 +768,7 @@ type AmortizationSchedule struct {
 
 type MonthlyPaymentsAdvanced struct {
 	XMLName xml.Name `xml:"paymentsdetails"`
+	base
 
 	Request MonthlyPaymentsAdvancedRequest `xml:"request"`
 	Message Message                        `xml:"message"`
@@ -496,10 +794,10 @@ type AffordabilityRequest struct {
 	Rate           float32 `xml:"rate"`
 	Schedule       string  `xml:"schedule"`
 	TermInMonths   int     `xml:"terminmonths"`
-	DebtToIncome   float32 `xml:"debttoincome"`
-	IncomeTax      float32 `xml:"incometax"`
+	DebtToIncome   Percent `xml:"debttoincome"`
+	IncomeTax      Percent `xml:"incometax"`
 	Estimate       bool    `xml:"estimate"`
-	PropertyTax    float32 `xml:"propertytax"`
+	PropertyTax    Percent `xml:"propertytax"`
 	Hazard         int     `xml:"hazard"`
 	PMI            int     `xml:"pmi"`
 	HOA            int     `xml:"hoa"`
@@ -522,6 +820,7 @@ type AffordabilityAmortizationSchedule struct {
 
 type Affordability struct {
 	XMLName xml.Name `xml:"affordabilitydetails"`
+	base
 
 	Request AffordabilityRequest `xml:"request"`
 	Message Message              `xml:"message"`
@@ -559,7 +858,7 @@ const (
 	countParam          = "count"
 	cityParam           = "city"
 	stateParam          = "state"
-	neighboorhoodParam  = "neightborhood"
+	neighborhoodParam   = "neighborhood"
 	zipParam            = "zip"
 	countryParam        = "country"
 	childTypeParam      = "childtype"
@@ -572,13 +871,13 @@ const (
 	scheduleParam       = "schedule"
 	termInMonthsParam   = "terminmonths"
 	propertyTaxParam    = "propertytax"
-	hazardParam         = "hazardparam"
+	hazardParam         = "hazard"
 	pmiParam            = "pmi"
 	hoaParam            = "hoa"
 	annualIncomeParam   = "annualincome"
-	monthlyPaymentParam = "monthlypayments"
+	monthlyPaymentParam = "monthlypayment"
 	monthlyDebtsParam   = "monthlydebts"
-	debtToIncomeParam   = "debtsinincome"
+	debtToIncomeParam   = "debttoincome"
 	incomeTaxParam      = "incometax"
 	estimateParam       = "estimate"
 )
@@ -599,43 +898,257 @@ const (
 	affordabilityPath           = "CalculateAffordability"
 )
 
+// zillow is the Zillow implementation. The zws-id is only ever sent as a query
+// parameter; request types deliberately have no field for it, so it is never
+// decoded into the Request echoed back on a result.
 type zillow struct {
 	zwsId string
 	url   string
+
+	client *http.Client
+	retry  *retryPolicy
+	// baseCtx is the WithBaseContext context, if set.
+	baseCtx context.Context
+	// retryBudget is the total backoff allowed per batch call, if set.
+	retryBudget          time.Duration
+	defaultRentZestimate bool
+	echoCheck            bool
+	// minComps is the fewest comparables a comps call may return, if set.
+	minComps int
+	// strictCompsCount rejects comps counts over the maximum instead of
+	// lowering them.
+	strictCompsCount bool
+	// strictSingleResult rejects searches with more than one result.
+	strictSingleResult bool
+	// sem limits calls in flight across batch methods, if set.
+	sem chan struct{}
+	// storeDir and replayDir are the directories set by WithResponseStore
+	// and WithReplayFrom.
+	storeDir  string
+	replayDir string
+	// pathSuffix overrides the default ".htm" suffix, if set.
+	pathSuffix     *string
+	acceptLanguage string
+	clock          func() time.Time
+	observer       Observer
+
+	// randMu guards rand, the source of retry jitter.
+	randMu sync.Mutex
+	rand   *rand.Rand
+
+	capabilitiesMu sync.Mutex
+	capabilities   map[string]bool
+}
+
+// checkEcho returns a *RequestMismatchError if echo checking is enabled and
+// Zillow echoed back a different value for param than was sent.
+func (z *zillow) checkEcho(param, sent, echoed string) error {
+	if !z.echoCheck || echoed == "" || strings.TrimSpace(sent) == strings.TrimSpace(echoed) {
+		return nil
+	}
+	return &RequestMismatchError{Param: param, Sent: sent, Echoed: echoed}
 }
 
-func (z *zillow) get(path string, values url.Values, result interface{}) error {
-	if resp, err := http.Get(z.url + "/" + path + ".htm?" + values.Encode()); err != nil {
+func (z *zillow) httpClient() *http.Client {
+	if z.client != nil {
+		return z.client
+	}
+	return http.DefaultClient
+}
+
+// get fetches path for request and decodes the response into result, retrying
+// transient failures according to the retry policy.
+func (z *zillow) get(ctx context.Context, path string, request Request, result interface{}) error {
+	ctx, cancel, err := z.mergeBaseContext(ctx)
+	if err != nil {
 		return err
-	} else if err = xml.NewDecoder(resp.Body).Decode(result); err != nil {
+	}
+	defer cancel()
+	for attempt := 1; ; attempt++ {
+		stats := CallStats{Endpoint: path, Attempt: attempt}
+		err := z.getOnce(ctx, path, request, result, &stats)
+		stats.Err = err
+		z.observe(stats)
+		// A result reporting a transient failure may be retried too, but is
+		// returned as is once retries run out.
+		retryErr := err
+		if err == nil {
+			retryErr = messageErr(result)
+		}
+		if retryErr == nil || !z.retry.shouldRetry(path, attempt, retryErr) {
+			return err
+		}
+		if !z.retry.wait(ctx, attempt, z.jitter) {
+			return err
+		}
+	}
+}
+
+// mergeBaseContext returns a ctx which is also done when the WithBaseContext
+// context is, if set, and fails if that is done already. The caller must call
+// cancel when done.
+func (z *zillow) mergeBaseContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if z.baseCtx == nil {
+		return ctx, func() {}, nil
+	}
+	if err := z.baseCtx.Err(); err != nil {
+		return nil, nil, err
+	}
+	merged, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-z.baseCtx.Done():
+			cancel()
+		case <-merged.Done():
+		}
+	}()
+	return merged, cancel, nil
+}
+
+// now returns the current time from the clock set by WithClock, if any.
+func (z *zillow) now() time.Time {
+	if z.clock == nil {
+		return time.Now()
+	}
+	return z.clock()
+}
+
+// suffix returns what is appended to each endpoint path.
+func (z *zillow) suffix() string {
+	if z.pathSuffix == nil {
+		return ".htm"
+	}
+	return *z.pathSuffix
+}
+
+// do issues a request for path. The caller must close the response body.
+func (z *zillow) do(ctx context.Context, path string, values url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, z.url+"/"+path+z.suffix()+"?"+values.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if z.acceptLanguage != "" {
+		req.Header.Set("Accept-Language", z.acceptLanguage)
+	}
+	return z.httpClient().Do(req)
+}
+
+// getOnce makes a single attempt of get, recording the body size and decode
+// time in stats. The body is read in full before decoding, unless result is
+// decoded incrementally from the live body.
+func (z *zillow) getOnce(ctx context.Context, path string, request Request, result interface{}, stats *CallStats) error {
+	rc, fetched, err := z.body(ctx, path, request)
+	if err != nil {
 		return err
 	}
+	defer rc.Close()
+	counter := &countingReader{r: rc}
+	buffered := bufio.NewReaderSize(counter, maxSnippet)
+	// Peek fails at the end of a shorter body, which is checked regardless.
+	head, _ := buffered.Peek(maxSnippet)
+	if err := checkNotHTML(head); err != nil {
+		return err
+	}
+	var r io.Reader = buffered
+	var saved *bytes.Buffer
+	if z.storeDir != "" {
+		saved = &bytes.Buffer{}
+		r = io.TeeReader(r, saved)
+	}
+	if _, ok := result.(interface{ incremental() }); !ok {
+		body, err := ioutil.ReadAll(r)
+		if err != nil {
+			stats.BodyBytes = counter.n
+			return &DecodeError{Err: err}
+		}
+		r = bytes.NewReader(body)
+	}
+	// Clear anything left over from a previous attempt.
+	if resetter, ok := result.(interface{ reset() }); ok {
+		resetter.reset()
+	} else {
+		v := reflect.ValueOf(result).Elem()
+		v.Set(reflect.Zero(v.Type()))
+	}
+	start := time.Now()
+	err = decodeReader(r, result)
+	stats.DecodeDuration = time.Since(start)
+	// Read anything after the root element, to count and save it too.
+	io.Copy(ioutil.Discard, r)
+	stats.BodyBytes = counter.n
+	if err != nil {
+		return &DecodeError{Err: err}
+	}
+	if f, ok := result.(interface{ setFetched(time.Time) }); ok {
+		f.setFetched(fetched)
+	}
+	if saved != nil {
+		stats.StoreErr = z.store(path, request, saved.Bytes(), fetched)
+	}
 	return nil
 }
 
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+// body returns the response body for path and when it was fetched, from the
+// replay directory if set. The caller must close the body.
+func (z *zillow) body(ctx context.Context, path string, request Request) (io.ReadCloser, time.Time, error) {
+	if z.replayDir != "" {
+		body, fetched, err := z.replay(path, request)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return ioutil.NopCloser(bytes.NewReader(body)), fetched, nil
+	}
+	fetched := z.now()
+	resp, err := z.do(ctx, path, z.values(request))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return resp.Body, fetched, nil
+}
+
 func (z *zillow) GetZestimate(request ZestimateRequest) (*ZestimateResult, error) {
-	values := url.Values{
-		zwsIdParam:         {z.zwsId},
-		zpidParam:          {request.Zpid},
-		rentzestimateParam: {strconv.FormatBool(request.Rentzestimate)},
+	return z.zestimate(context.Background(), request)
+}
+
+func (z *zillow) zestimate(ctx context.Context, request ZestimateRequest) (*ZestimateResult, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
 	}
 	var result ZestimateResult
-	if err := z.get(zestimatePath, values, &result); err != nil {
+	if err := z.get(ctx, zestimatePath, request, &result); err != nil {
+		return nil, err
+	} else if err := z.checkEcho(zpidParam, request.Zpid, result.Request.Zpid); err != nil {
 		return nil, err
 	} else {
+		result.RentZestimateStatus = rentZestimateStatus(z.effective(request).(ZestimateRequest).Rentzestimate, result.RentZestimate)
 		return &result, nil
 	}
 }
 
 func (z *zillow) GetSearchResults(request SearchRequest) (*SearchResults, error) {
-	values := url.Values{
-		zwsIdParam:         {z.zwsId},
-		addressParam:       {request.Address},
-		cityStateZipParam:  {request.CityStateZip},
-		rentzestimateParam: {strconv.FormatBool(request.Rentzestimate)},
+	return z.searchResults(context.Background(), request)
+}
+
+func (z *zillow) searchResults(ctx context.Context, request SearchRequest) (*SearchResults, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
 	}
 	var result SearchResults
-	if err := z.get(searchResultsPath, values, &result); err != nil {
+	if err := z.get(ctx, searchResultsPath, request, &result); err != nil {
+		return nil, err
+	} else if err := z.checkSearch(request, result.Request, resultZpids(result.Results)); err != nil {
 		return nil, err
 	} else {
 		return &result, nil
@@ -643,16 +1156,17 @@ func (z *zillow) GetSearchResults(request SearchRequest) (*SearchResults, error)
 }
 
 func (z *zillow) GetChart(request ChartRequest) (*ChartResult, error) {
-	values := url.Values{
-		zwsIdParam:         {z.zwsId},
-		zpidParam:          {request.Zpid},
-		unitTypeParam:      {request.UnitType},
-		widthParam:         {strconv.Itoa(request.Width)},
-		heightParam:        {strconv.Itoa(request.Height)},
-		chartDurationParam: {request.Duration},
+	return z.chart(context.Background(), request)
+}
+
+func (z *zillow) chart(ctx context.Context, request ChartRequest) (*ChartResult, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
 	}
 	var result ChartResult
-	if err := z.get(chartPath, values, &result); err != nil {
+	if err := z.get(ctx, chartPath, request, &result); err != nil {
+		return nil, err
+	} else if err := z.checkEcho(zpidParam, request.Zpid, result.Request.Zpid); err != nil {
 		return nil, err
 	} else {
 		return &result, nil
@@ -660,14 +1174,25 @@ func (z *zillow) GetChart(request ChartRequest) (*ChartResult, error) {
 }
 
 func (z *zillow) GetComps(request CompsRequest) (*CompsResult, error) {
-	values := url.Values{
-		zwsIdParam:         {z.zwsId},
-		zpidParam:          {request.Zpid},
-		countParam:         {strconv.Itoa(request.Count)},
-		rentzestimateParam: {strconv.FormatBool(request.Rentzestimate)},
+	return z.comps(context.Background(), request)
+}
+
+func (z *zillow) comps(ctx context.Context, request CompsRequest) (*CompsResult, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+	request, err := z.compsRequest(request)
+	if err != nil {
+		return nil, err
 	}
 	var result CompsResult
-	if err := z.get(compsPath, values, &result); err != nil {
+	if err := z.get(ctx, compsPath, request, &result); err != nil {
+		return nil, err
+	} else if err := z.checkEcho(zpidParam, request.Zpid, result.Request.Zpid); err != nil {
+		return nil, err
+	} else if err := checkCount(result.Message, request.Count); err != nil {
+		return nil, err
+	} else if err := z.checkMinComps(result.Message, len(result.Comparables)); err != nil {
 		return nil, err
 	} else {
 		return &result, nil
@@ -675,14 +1200,25 @@ func (z *zillow) GetComps(request CompsRequest) (*CompsResult, error) {
 }
 
 func (z *zillow) GetDeepComps(request CompsRequest) (*DeepCompsResult, error) {
-	values := url.Values{
-		zwsIdParam:         {z.zwsId},
-		zpidParam:          {request.Zpid},
-		countParam:         {strconv.Itoa(request.Count)},
-		rentzestimateParam: {strconv.FormatBool(request.Rentzestimate)},
+	return z.deepComps(context.Background(), request)
+}
+
+func (z *zillow) deepComps(ctx context.Context, request CompsRequest) (*DeepCompsResult, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+	request, err := z.compsRequest(request)
+	if err != nil {
+		return nil, err
 	}
 	var result DeepCompsResult
-	if err := z.get(deepCompsPath, values, &result); err != nil {
+	if err := z.get(ctx, deepCompsPath, request, &result); err != nil {
+		return nil, err
+	} else if err := z.checkEcho(zpidParam, request.Zpid, result.Request.Zpid); err != nil {
+		return nil, err
+	} else if err := checkCount(result.Message, request.Count); err != nil {
+		return nil, err
+	} else if err := z.checkMinComps(result.Message, len(result.Comparables)); err != nil {
 		return nil, err
 	} else {
 		return &result, nil
@@ -690,14 +1226,17 @@ func (z *zillow) GetDeepComps(request CompsRequest) (*DeepCompsResult, error) {
 }
 
 func (z *zillow) GetDeepSearchResults(request SearchRequest) (*DeepSearchResults, error) {
-	values := url.Values{
-		zwsIdParam:         {z.zwsId},
-		addressParam:       {request.Address},
-		cityStateZipParam:  {request.CityStateZip},
-		rentzestimateParam: {strconv.FormatBool(request.Rentzestimate)},
+	return z.deepSearchResults(context.Background(), request)
+}
+
+func (z *zillow) deepSearchResults(ctx context.Context, request SearchRequest) (*DeepSearchResults, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
 	}
 	var result DeepSearchResults
-	if err := z.get(deepSearchPath, values, &result); err != nil {
+	if err := z.get(ctx, deepSearchPath, request, &result); err != nil {
+		return nil, err
+	} else if err := z.checkSearch(request, result.Request, deepResultZpids(result.Results)); err != nil {
 		return nil, err
 	} else {
 		return &result, nil
@@ -705,12 +1244,17 @@ func (z *zillow) GetDeepSearchResults(request SearchRequest) (*DeepSearchResults
 }
 
 func (z *zillow) GetUpdatedPropertyDetails(request UpdatedPropertyDetailsRequest) (*UpdatedPropertyDetails, error) {
-	values := url.Values{
-		zwsIdParam: {z.zwsId},
-		zpidParam:  {request.Zpid},
+	return z.updatedPropertyDetails(context.Background(), request)
+}
+
+func (z *zillow) updatedPropertyDetails(ctx context.Context, request UpdatedPropertyDetailsRequest) (*UpdatedPropertyDetails, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
 	}
 	var result UpdatedPropertyDetails
-	if err := z.get(updatedPropertyDetailsPath, values, &result); err != nil {
+	if err := z.get(ctx, updatedPropertyDetailsPath, request, &result); err != nil {
+		return nil, err
+	} else if err := z.checkEcho(zpidParam, request.Zpid, result.Request.Zpid); err != nil {
 		return nil, err
 	} else {
 		return &result, nil
@@ -718,16 +1262,12 @@ func (z *zillow) GetUpdatedPropertyDetails(request UpdatedPropertyDetailsRequest
 }
 
 func (z *zillow) GetRegionChildren(request RegionChildrenRequest) (*RegionChildren, error) {
-	values := url.Values{
-		zwsIdParam:     {z.zwsId},
-		regionIdParam:  {request.RegionId},
-		stateParam:     {request.State},
-		countryParam:   {request.Country},
-		cityParam:      {request.City},
-		childTypeParam: {request.ChildType},
-	}
+	return z.regionChildren(context.Background(), request)
+}
+
+func (z *zillow) regionChildren(ctx context.Context, request RegionChildrenRequest) (*RegionChildren, error) {
 	var result RegionChildren
-	if err := z.get(regionChildrenPath, values, &result); err != nil {
+	if err := z.get(ctx, regionChildrenPath, request, &result); err != nil {
 		return nil, err
 	} else {
 		return &result, nil
@@ -735,19 +1275,15 @@ func (z *zillow) GetRegionChildren(request RegionChildrenRequest) (*RegionChildr
 }
 
 func (z *zillow) GetRegionChart(request RegionChartRequest) (*RegionChartResult, error) {
-	values := url.Values{
-		zwsIdParam:         {z.zwsId},
-		cityParam:          {request.City},
-		stateParam:         {request.State},
-		neighboorhoodParam: {request.Neighborhood},
-		zipParam:           {request.Zipcode},
-		unitTypeParam:      {request.UnitType},
-		widthParam:         {strconv.Itoa(request.Width)},
-		heightParam:        {strconv.Itoa(request.Height)},
-		chartDurationParam: {request.ChartDuration},
+	return z.regionChart(context.Background(), request)
+}
+
+func (z *zillow) regionChart(ctx context.Context, request RegionChartRequest) (*RegionChartResult, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
 	}
 	var result RegionChartResult
-	if err := z.get(regionChartPath, values, &result); err != nil {
+	if err := z.get(ctx, regionChartPath, request, &result); err != nil {
 		return nil, err
 	} else {
 		return &result, nil
@@ -755,12 +1291,12 @@ func (z *zillow) GetRegionChart(request RegionChartRequest) (*RegionChartResult,
 }
 
 func (z *zillow) GetRateSummary(request RateSummaryRequest) (*RateSummary, error) {
-	values := url.Values{
-		zwsIdParam: {z.zwsId},
-		stateParam: {request.State},
-	}
+	return z.rateSummary(context.Background(), request)
+}
+
+func (z *zillow) rateSummary(ctx context.Context, request RateSummaryRequest) (*RateSummary, error) {
 	var result RateSummary
-	if err := z.get(rateSummaryPath, values, &result); err != nil {
+	if err := z.get(ctx, rateSummaryPath, request, &result); err != nil {
 		return nil, err
 	} else {
 		return &result, nil
@@ -768,15 +1304,15 @@ func (z *zillow) GetRateSummary(request RateSummaryRequest) (*RateSummary, error
 }
 
 func (z *zillow) GetMonthlyPayments(request MonthlyPaymentsRequest) (*MonthlyPayments, error) {
-	values := url.Values{
-		zwsIdParam:       {z.zwsId},
-		priceParam:       {strconv.Itoa(request.Price)},
-		downParam:        {strconv.Itoa(request.Down)},
-		dollarsDownParam: {strconv.Itoa(request.DollarsDown)},
-		zipParam:         {request.Zip},
+	return z.monthlyPayments(context.Background(), request)
+}
+
+func (z *zillow) monthlyPayments(ctx context.Context, request MonthlyPaymentsRequest) (*MonthlyPayments, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
 	}
 	var result MonthlyPayments
-	if err := z.get(monthlyPaymentsPath, values, &result); err != nil {
+	if err := z.get(ctx, monthlyPaymentsPath, request, &result); err != nil {
 		return nil, err
 	} else {
 		return &result, nil
@@ -784,22 +1320,15 @@ func (z *zillow) GetMonthlyPayments(request MonthlyPaymentsRequest) (*MonthlyPay
 }
 
 func (z *zillow) CalculateMonthlyPaymentsAdvanced(request MonthlyPaymentsAdvancedRequest) (*MonthlyPaymentsAdvanced, error) {
-	values := url.Values{
-		zwsIdParam:        {z.zwsId},
-		priceParam:        {strconv.Itoa(request.Price)},
-		downParam:         {strconv.Itoa(request.Down)},
-		amountParam:       {strconv.Itoa(request.Amount)},
-		rateParam:         {strconv.FormatFloat(float64(request.Rate), 'f', -1, 32)},
-		scheduleParam:     {request.Schedule},
-		termInMonthsParam: {strconv.Itoa(request.TermInMonths)},
-		propertyTaxParam:  {strconv.Itoa(request.PropertyTax)},
-		hazardParam:       {strconv.Itoa(request.Hazard)},
-		pmiParam:          {strconv.Itoa(request.PMI)},
-		hoaParam:          {strconv.Itoa(request.HOA)},
-		zipParam:          {request.Zip},
+	return z.monthlyPaymentsAdvanced(context.Background(), request)
+}
+
+func (z *zillow) monthlyPaymentsAdvanced(ctx context.Context, request MonthlyPaymentsAdvancedRequest) (*MonthlyPaymentsAdvanced, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
 	}
 	var result MonthlyPaymentsAdvanced
-	if err := z.get(monthlyPaymentsAdvancedPath, values, &result); err != nil {
+	if err := z.get(ctx, monthlyPaymentsAdvancedPath, request, &result); err != nil {
 		return nil, err
 	} else {
 		return &result, nil
@@ -807,26 +1336,15 @@ func (z *zillow) CalculateMonthlyPaymentsAdvanced(request MonthlyPaymentsAdvance
 }
 
 func (z *zillow) CalculateAffordability(request AffordabilityRequest) (*Affordability, error) {
-	values := url.Values{
-		zwsIdParam:          {z.zwsId},
-		annualIncomeParam:   {strconv.Itoa(request.AnnualIncome)},
-		monthlyPaymentParam: {strconv.Itoa(request.MonthlyPayment)},
-		downParam:           {strconv.Itoa(request.Down)},
-		monthlyDebtsParam:   {strconv.Itoa(request.MonthlyDebts)},
-		rateParam:           {strconv.FormatFloat(float64(request.Rate), 'f', -1, 32)},
-		scheduleParam:       {request.Schedule},
-		termInMonthsParam:   {strconv.Itoa(request.TermInMonths)},
-		debtToIncomeParam:   {strconv.FormatFloat(float64(request.DebtToIncome), 'f', -1, 32)},
-		incomeTaxParam:      {strconv.FormatFloat(float64(request.IncomeTax), 'f', -1, 32)},
-		estimateParam:       {strconv.FormatBool(request.Estimate)},
-		propertyTaxParam:    {strconv.FormatFloat(float64(request.PropertyTax), 'f', -1, 32)},
-		hazardParam:         {strconv.Itoa(request.Hazard)},
-		pmiParam:            {strconv.Itoa(request.PMI)},
-		hoaParam:            {strconv.Itoa(request.HOA)},
-		zipParam:            {request.Zip},
+	return z.affordability(context.Background(), request)
+}
+
+func (z *zillow) affordability(ctx context.Context, request AffordabilityRequest) (*Affordability, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
 	}
 	var result Affordability
-	if err := z.get(affordabilityPath, values, &result); err != nil {
+	if err := z.get(ctx, affordabilityPath, request, &result); err != nil {
 		return nil, err
 	} else {
 		return &result, nil
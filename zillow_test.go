@@ -3,7 +3,9 @@ package zillow
 import (
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -12,6 +14,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -47,6 +50,19 @@ const (
 	estimate       = false
 )
 
+// The client satisfies each service interface.
+var (
+	_ Zillow          = (*zillow)(nil)
+	_ Valuation       = (*zillow)(nil)
+	_ PropertyDetails = (*zillow)(nil)
+	_ Neighborhood    = (*zillow)(nil)
+	_ Mortgage        = (*zillow)(nil)
+)
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
 func assertOnlyParam(t *testing.T, values url.Values, param, expected string) {
 	if len(values[param]) != 1 {
 		t.Fatalf("expected single %q param", param)
@@ -56,7 +72,12 @@ func assertOnlyParam(t *testing.T, values url.Values, param, expected string) {
 	}
 }
 
-func testFixtures(t *testing.T, expectedPath string, validateQuery func(url.Values)) (*httptest.Server, Zillow) {
+func testFixtures(t *testing.T, expectedPath string, validateQuery func(url.Values), opts ...Option) (*httptest.Server, Zillow) {
+	return testFixture(t, expectedPath, expectedPath, validateQuery, opts...)
+}
+
+// testFixture is like testFixtures, but serves testdata/<fixture>.xml.
+func testFixture(t *testing.T, expectedPath, fixture string, validateQuery func(url.Values), opts ...Option) (*httptest.Server, Zillow) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !strings.HasSuffix(r.URL.Path, expectedPath+".htm") {
 			t.Errorf("expected path %q to end with %q", r.URL.Path, expectedPath)
@@ -65,13 +86,17 @@ func testFixtures(t *testing.T, expectedPath string, validateQuery func(url.Valu
 		assertOnlyParam(t, values, zwsIdParam, testZwsId)
 		validateQuery(values)
 
-		if f, err := os.Open("testdata/" + expectedPath + ".xml"); err != nil {
+		if f, err := os.Open("testdata/" + fixture + ".xml"); err != nil {
 			t.Fatal(err)
 		} else if _, err := io.Copy(w, f); err != nil {
 			t.Fatal(err)
 		}
 	}))
-	return ts, &zillow{zwsId: testZwsId, url: ts.URL}
+	z := &zillow{zwsId: testZwsId, url: ts.URL}
+	for _, opt := range opts {
+		opt(z)
+	}
+	return ts, z
 }
 
 func TestGetZestimate(t *testing.T) {
@@ -88,11 +113,13 @@ func TestGetZestimate(t *testing.T) {
 	}
 	expected := &ZestimateResult{
 		XMLName: xml.Name{Space: "Zestimate", Local: "zestimate"},
+		base:    base{responsePresent: true},
 		Request: request,
 		Message: Message{
 			Text: "Request successfully processed",
 			Code: 0,
 		},
+		Zpid: zpid,
 		Links: Links{
 			XMLName:       xml.Name{Local: "links"},
 			HomeDetails:   "http://www.zillow.com/homedetails/2114-Bigelow-Ave-N-Seattle-WA-98109/48749425_zpid/",
@@ -109,12 +136,12 @@ func TestGetZestimate(t *testing.T) {
 			Longitude: "-122.347936",
 		},
 		Zestimate: Zestimate{
-			Amount:      Value{Currency: "USD", Value: 1219500},
+			Amount:      Value{Currency: "USD", Value: 1219500, Present: true},
 			LastUpdated: "11/03/2009",
-			//ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: -41500},
-			Percentile: "95",
-			Low:        Value{Currency: "USD", Value: 1024380},
-			High:       Value{Currency: "USD", Value: 1378035},
+			ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: -41500},
+			Percentile:  "95",
+			Low:         Value{Currency: "USD", Value: 1024380, Present: true},
+			High:        Value{Currency: "USD", Value: 1378035, Present: true},
 		},
 		LocalRealEstate: []RealEstateRegion{
 			{
@@ -123,7 +150,7 @@ func TestGetZestimate(t *testing.T) {
 				Type:                "neighborhood",
 				Name:                "East Queen Anne",
 				ZIndex:              "525,397",
-				ZIndexOneYearChange: -0.144,
+				ZIndexOneYearChange: float64Ptr(-0.144),
 				Overview:            "http://www.zillow.com/local-info/WA-Seattle/East-Queen-Anne/r_271856/",
 				ForSaleByOwner:      "http://www.zillow.com/homes/fsbo/East-Queen-Anne-Seattle-WA/",
 				ForSale:             "http://www.zillow.com/east-queen-anne-seattle-wa/",
@@ -134,7 +161,7 @@ func TestGetZestimate(t *testing.T) {
 				Type:                "city",
 				Name:                "Seattle",
 				ZIndex:              "381,764",
-				ZIndexOneYearChange: -0.074,
+				ZIndexOneYearChange: float64Ptr(-0.074),
 				Overview:            "http://www.zillow.com/local-info/WA-Seattle/r_16037/",
 				ForSaleByOwner:      "http://www.zillow.com/homes/fsbo/Seattle-WA/",
 				ForSale:             "http://www.zillow.com/seattle-wa/",
@@ -145,16 +172,17 @@ func TestGetZestimate(t *testing.T) {
 				Type:                "state",
 				Name:                "Washington",
 				ZIndex:              "263,278",
-				ZIndexOneYearChange: -0.066,
+				ZIndexOneYearChange: float64Ptr(-0.066),
 				Overview:            "http://www.zillow.com/local-info/WA-home-value/r_59/",
 				ForSaleByOwner:      "http://www.zillow.com/homes/fsbo/WA/",
 				ForSale:             "http://www.zillow.com/wa/",
 			},
 		},
-		ZipcodeID: "99569",
-		CityID:    "16037",
-		CountyID:  "207",
-		StateID:   "59",
+		RentZestimateStatus: RentZestimateNotRequested,
+		ZipcodeID:           "99569",
+		CityID:              "16037",
+		CountyID:            "207",
+		StateID:             "59",
 	}
 
 	if !reflect.DeepEqual(result, expected) {
@@ -185,6 +213,7 @@ func TestGetSearchResults(t *testing.T) {
 	}
 	expected := &SearchResults{
 		XMLName: xml.Name{Space: "SearchResults", Local: "searchresults"},
+		base:    base{responsePresent: true},
 		Request: request,
 		Message: Message{
 			Text: "Request successfully processed",
@@ -210,12 +239,12 @@ func TestGetSearchResults(t *testing.T) {
 					Longitude: "-122.347936",
 				},
 				Zestimate: Zestimate{
-					Amount:      Value{Currency: "USD", Value: 1219500},
+					Amount:      Value{Currency: "USD", Value: 1219500, Present: true},
 					LastUpdated: "11/03/2009",
-					//ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: -41500},
-					Low:        Value{Currency: "USD", Value: 1024380},
-					High:       Value{Currency: "USD", Value: 1378035},
-					Percentile: "0",
+					ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: -41500},
+					Low:         Value{Currency: "USD", Value: 1024380, Present: true},
+					High:        Value{Currency: "USD", Value: 1378035, Present: true},
+					Percentile:  "0",
 				},
 				LocalRealEstate: []RealEstateRegion{
 					{
@@ -224,7 +253,7 @@ func TestGetSearchResults(t *testing.T) {
 						Type:                "neighborhood",
 						Name:                "East Queen Anne",
 						ZIndex:              "525,397",
-						ZIndexOneYearChange: -0.144,
+						ZIndexOneYearChange: float64Ptr(-0.144),
 						Overview:            "http://www.zillow.com/local-info/WA-Seattle/East-Queen-Anne/r_271856/",
 						ForSaleByOwner:      "http://www.zillow.com/homes/fsbo/East-Queen-Anne-Seattle-WA/",
 						ForSale:             "http://www.zillow.com/east-queen-anne-seattle-wa/",
@@ -235,7 +264,7 @@ func TestGetSearchResults(t *testing.T) {
 						Type:                "city",
 						Name:                "Seattle",
 						ZIndex:              "381,764",
-						ZIndexOneYearChange: -0.074,
+						ZIndexOneYearChange: float64Ptr(-0.074),
 						Overview:            "http://www.zillow.com/local-info/WA-Seattle/r_16037/",
 						ForSaleByOwner:      "http://www.zillow.com/homes/fsbo/Seattle-WA/",
 						ForSale:             "http://www.zillow.com/seattle-wa/",
@@ -246,7 +275,7 @@ func TestGetSearchResults(t *testing.T) {
 						Type:                "state",
 						Name:                "Washington",
 						ZIndex:              "263,278",
-						ZIndexOneYearChange: -0.066,
+						ZIndexOneYearChange: float64Ptr(-0.066),
 						Overview:            "http://www.zillow.com/local-info/WA-home-value/r_59/",
 						ForSaleByOwner:      "http://www.zillow.com/homes/fsbo/WA/",
 						ForSale:             "http://www.zillow.com/wa/",
@@ -277,6 +306,7 @@ func TestGetChart(t *testing.T) {
 	}
 	expected := &ChartResult{
 		XMLName: xml.Name{Space: "http://www.zillowstatic.com/vstatic/8d9b5f1/static/xsd/Chart.xsd", Local: "chart"},
+		base:    base{responsePresent: true},
 		Request: request,
 		Message: Message{
 			Text: "Request successfully processed",
@@ -305,6 +335,7 @@ func TestGetComps(t *testing.T) {
 	}
 	expected := &CompsResult{
 		XMLName: xml.Name{Space: "http://www.zillowstatic.com/vstatic/8d9b5f1/static/xsd/Comps.xsd", Local: "comps"},
+		base:    base{responsePresent: true},
 		Request: request,
 		Message: Message{
 			Text: "Request successfully processed",
@@ -328,10 +359,10 @@ func TestGetComps(t *testing.T) {
 				Longitude: "-122.347936",
 			},
 			Zestimate: Zestimate{
-				Amount:      Value{Currency: "USD", Value: 1124072},
+				Amount:      Value{Currency: "USD", Value: 1124072, Present: true},
 				LastUpdated: "09/01/2006",
-				Low:         Value{Currency: "USD", Value: 966702},
-				High:        Value{Currency: "USD", Value: 1236479},
+				Low:         Value{Currency: "USD", Value: 966702, Present: true},
+				High:        Value{Currency: "USD", Value: 1236479, Present: true},
 				Percentile:  "93",
 			},
 		},
@@ -356,10 +387,10 @@ func TestGetComps(t *testing.T) {
 					Longitude: "-122.347385",
 				},
 				Zestimate: Zestimate{
-					Amount:      Value{Currency: "USD", Value: 985000},
+					Amount:      Value{Currency: "USD", Value: 985000, Present: true},
 					LastUpdated: "09/01/2006",
-					Low:         Value{Currency: "USD", Value: 847100},
-					High:        Value{Currency: "USD", Value: 1083500},
+					Low:         Value{Currency: "USD", Value: 847100, Present: true},
+					High:        Value{Currency: "USD", Value: 1083500, Present: true},
 				},
 			},
 			{
@@ -382,10 +413,10 @@ func TestGetComps(t *testing.T) {
 					Longitude: "-122.348008",
 				},
 				Zestimate: Zestimate{
-					Amount:      Value{Currency: "USD", Value: 1326256},
+					Amount:      Value{Currency: "USD", Value: 1326256, Present: true},
 					LastUpdated: "09/01/2006",
-					Low:         Value{Currency: "USD", Value: 1140580},
-					High:        Value{Currency: "USD", Value: 1458882},
+					Low:         Value{Currency: "USD", Value: 1140580, Present: true},
+					High:        Value{Currency: "USD", Value: 1458882, Present: true},
 				},
 			},
 		},
@@ -411,6 +442,7 @@ func TestGetDeepComp(t *testing.T) {
 	}
 	expected := &DeepCompsResult{
 		XMLName: xml.Name{Space: "Comps", Local: "comps"},
+		base:    base{responsePresent: true},
 		Request: request,
 		Message: Message{
 			Text: "Request successfully processed",
@@ -442,14 +474,14 @@ func TestGetDeepComp(t *testing.T) {
 			Bathrooms:        3.0,
 			Bedrooms:         4,
 			LastSoldDate:     "11/26/2008",
-			LastSoldPrice:    Value{Currency: "USD", Value: 995000},
+			LastSoldPrice:    Value{Currency: "USD", Value: 995000, Present: true},
 			Zestimate: Zestimate{
-				Amount:      Value{Currency: "USD", Value: 1219500},
+				Amount:      Value{Currency: "USD", Value: 1219500, Present: true},
 				LastUpdated: "12/31/1969",
-				//ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: -41500},
-				Low:        Value{Currency: "USD", Value: 1024380},
-				High:       Value{Currency: "USD", Value: 1378035},
-				Percentile: "95",
+				ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: -41500},
+				Low:         Value{Currency: "USD", Value: 1024380, Present: true},
+				High:        Value{Currency: "USD", Value: 1378035, Present: true},
+				Percentile:  "95",
 			},
 			LocalRealEstate: []RealEstateRegion{
 				{
@@ -458,7 +490,7 @@ func TestGetDeepComp(t *testing.T) {
 					Type:                "neighborhood",
 					Name:                "East Queen Anne",
 					ZIndex:              "525,397",
-					ZIndexOneYearChange: -0.144,
+					ZIndexOneYearChange: float64Ptr(-0.144),
 					Overview:            "http://www.zillow.com/local-info/WA-Seattle/East-Queen-Anne/r_271856/",
 					ForSaleByOwner:      "http://www.zillow.com/homes/fsbo/East-Queen-Anne-Seattle-WA/",
 					ForSale:             "http://www.zillow.com/east-queen-anne-seattle-wa/",
@@ -469,7 +501,7 @@ func TestGetDeepComp(t *testing.T) {
 					Type:                "city",
 					Name:                "Seattle",
 					ZIndex:              "381,764",
-					ZIndexOneYearChange: -0.074,
+					ZIndexOneYearChange: float64Ptr(-0.074),
 					Overview:            "http://www.zillow.com/local-info/WA-Seattle/r_16037/",
 					ForSaleByOwner:      "http://www.zillow.com/homes/fsbo/Seattle-WA/",
 					ForSale:             "http://www.zillow.com/seattle-wa/",
@@ -480,7 +512,7 @@ func TestGetDeepComp(t *testing.T) {
 					Type:                "state",
 					Name:                "Washington",
 					ZIndex:              "263,278",
-					ZIndexOneYearChange: -0.066,
+					ZIndexOneYearChange: float64Ptr(-0.066),
 					Overview:            "http://www.zillow.com/local-info/WA-home-value/r_59/",
 					ForSaleByOwner:      "http://www.zillow.com/homes/fsbo/WA/",
 					ForSale:             "http://www.zillow.com/wa/",
@@ -514,14 +546,14 @@ func TestGetDeepComp(t *testing.T) {
 				Bathrooms:        4,
 				Bedrooms:         4,
 				LastSoldDate:     "09/24/2009",
-				LastSoldPrice:    Value{Currency: "USD", Value: 832500},
+				LastSoldPrice:    Value{Currency: "USD", Value: 832500, Present: true},
 				Zestimate: Zestimate{
-					Amount:      Value{Currency: "USD", Value: 836500},
+					Amount:      Value{Currency: "USD", Value: 836500, Present: true},
 					LastUpdated: "11/03/2009",
-					//ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: -220500},
-					Low:        Value{Currency: "USD", Value: 777945},
-					High:       Value{Currency: "USD", Value: 886690},
-					Percentile: "83",
+					ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: -220500},
+					Low:         Value{Currency: "USD", Value: 777945, Present: true},
+					High:        Value{Currency: "USD", Value: 886690, Present: true},
+					Percentile:  "83",
 				},
 			},
 			{
@@ -551,14 +583,14 @@ func TestGetDeepComp(t *testing.T) {
 				Bathrooms:        2,
 				Bedrooms:         2,
 				LastSoldDate:     "08/20/2009",
-				LastSoldPrice:    Value{Currency: "USD", Value: 595000},
+				LastSoldPrice:    Value{Currency: "USD", Value: 595000, Present: true},
 				Zestimate: Zestimate{
-					Amount:      Value{Currency: "USD", Value: 608000},
+					Amount:      Value{Currency: "USD", Value: 608000, Present: true},
 					LastUpdated: "11/03/2009",
-					//ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: 11000},
-					Low:        Value{Currency: "USD", Value: 559360},
-					High:       Value{Currency: "USD", Value: 656640},
-					Percentile: "68",
+					ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: 11000},
+					Low:         Value{Currency: "USD", Value: 559360, Present: true},
+					High:        Value{Currency: "USD", Value: 656640, Present: true},
+					Percentile:  "68",
 				},
 			},
 		},
@@ -584,6 +616,7 @@ func TestGetDeepSearchResults(t *testing.T) {
 	}
 	expected := &DeepSearchResults{
 		XMLName: xml.Name{Space: "SearchResults", Local: "searchresults"},
+		base:    base{responsePresent: true},
 		Request: request,
 		Message: Message{
 			Text: "Request successfully processed",
@@ -618,14 +651,14 @@ func TestGetDeepSearchResults(t *testing.T) {
 				Bathrooms:         3.0,
 				Bedrooms:          4,
 				LastSoldDate:      "11/26/2008",
-				LastSoldPrice:     Value{Currency: "USD", Value: 995000},
+				LastSoldPrice:     Value{Currency: "USD", Value: 995000, Present: true},
 				Zestimate: Zestimate{
-					Amount:      Value{Currency: "USD", Value: 1219500},
+					Amount:      Value{Currency: "USD", Value: 1219500, Present: true},
 					LastUpdated: "12/31/1969",
-					//ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: -41500},
-					Low:        Value{Currency: "USD", Value: 1024380},
-					High:       Value{Currency: "USD", Value: 1378035},
-					Percentile: "0",
+					ValueChange: ValueChange{Duration: 30, Currency: "USD", Value: -41500},
+					Low:         Value{Currency: "USD", Value: 1024380, Present: true},
+					High:        Value{Currency: "USD", Value: 1378035, Present: true},
+					Percentile:  "0",
 				},
 				LocalRealEstate: []RealEstateRegion{
 					{
@@ -683,6 +716,7 @@ func TestGetUpdatedPropertyDetails(t *testing.T) {
 	}
 	expected := &UpdatedPropertyDetails{
 		XMLName: xml.Name{Space: "http://www.zillow.com/static/xsd/UpdatedPropertyDetails.xsd", Local: "updatedPropertyDetails"},
+		base:    base{responsePresent: true},
 		Request: request,
 		Message: Message{
 			Text: "Request successfully processed",
@@ -698,8 +732,19 @@ func TestGetUpdatedPropertyDetails(t *testing.T) {
 			Latitude:  "47.637924",
 			Longitude: "-122.347929",
 		},
-		Price: Value{Currency: "USD", Value: 1290000},
+		Price: Value{Currency: "USD", Value: 1290000, Present: true},
 		Posting: Posting{
+			Agent: Agent{
+				Name:       "John Blacksmith",
+				ProfileURL: "/profile/John.Blacksmith",
+			},
+			Listing: Listing{
+				Status:      "Active",
+				Type:        "For sale by agent",
+				MLS:         "28097669",
+				ExternalURL: "http://mls.lakere.com/srch_mls/detail.php?mode=ag&LN=28097669&t=listings&l=",
+				LastUpdated: "2008-06-05 10:28:00.0",
+			},
 			Status:          "Active",
 			AgentName:       "John Blacksmith",
 			AgentProfileUrl: "/profile/John.Blacksmith",
@@ -741,6 +786,14 @@ func TestGetUpdatedPropertyDetails(t *testing.T) {
 			FloorCovering:  "Hardwood, Carpet, Tile",
 			Rooms:          "Laundry room, Walk-in closet, Master bath, Office, Dining room, Family room, Breakfast nook",
 		},
+		Schools: Schools{
+			District:   "Seattle",
+			Elementary: "John Hay",
+			Middle:     "McClure",
+		},
+		SchoolDistrict:   "Seattle",
+		ElementarySchool: "John Hay",
+		MiddleSchool:     "McClure",
 	}
 
 	if !reflect.DeepEqual(result, expected) {
@@ -767,6 +820,7 @@ func TestGetRegionChildren(t *testing.T) {
 	}
 	expected := &RegionChildren{
 		XMLName: xml.Name{Space: "http://www.zillow.com/static/xsd/RegionChildren.xsd", Local: "regionchildren"},
+		base:    base{responsePresent: true},
 		Request: request,
 		Message: Message{
 			Text: "Request successfully processed",
@@ -787,7 +841,7 @@ func TestGetRegionChildren(t *testing.T) {
 			{
 				Id:        "343997",
 				Name:      "Alki",
-				ZIndex:    Value{Currency: "USD", Value: 537360},
+				ZIndex:    Value{Currency: "USD", Value: 537360, Present: true},
 				Url:       "http://www.zillow.com/real-estate/WA-Seattle/Alki",
 				Latitude:  "47.56955",
 				Longitude: "-122.397729",
@@ -795,7 +849,7 @@ func TestGetRegionChildren(t *testing.T) {
 			{
 				Id:        "250788",
 				Name:      "Greenwood",
-				ZIndex:    Value{Currency: "USD", Value: 433246},
+				ZIndex:    Value{Currency: "USD", Value: 433246, Present: true},
 				Url:       "http://www.zillow.com/real-estate/WA-Seattle/Greenwood",
 				Latitude:  "47.694114",
 				Longitude: "-122.355228",
@@ -803,7 +857,7 @@ func TestGetRegionChildren(t *testing.T) {
 			{
 				Id:        "252248",
 				Name:      "Wallingford",
-				ZIndex:    Value{Currency: "USD", Value: 591847},
+				ZIndex:    Value{Currency: "USD", Value: 591847, Present: true},
 				Url:       "http://www.zillow.com/real-estate/WA-Seattle/Wallingford",
 				Latitude:  "47.659711",
 				Longitude: "-122.333821",
@@ -839,13 +893,14 @@ func TestGetRegionChart(t *testing.T) {
 	}
 	expected := &RegionChartResult{
 		XMLName: xml.Name{Space: "http://www.zillow.com/static/xsd/RegionChart.xsd", Local: "regionchart"},
+		base:    base{responsePresent: true},
 		Request: request,
 		Message: Message{
 			Text: "Request successfully processed",
 			Code: 0,
 		},
 		Url:    "http://localhost:8080/app?chartDuration=1year&chartType=partner&cityRegionId=5470&countyRegionId=0&height=150&nationRegionId=0&page=webservice%2FGetRegionChart&service=chart&showCity=true&showPercent=true&stateRegionId=0&width=300&zipRegionId=0",
-		Zindex: Value{Currency: "USD", Value: 463115},
+		Zindex: Value{Currency: "USD", Value: 463115, Present: true},
 	}
 
 	if !reflect.DeepEqual(result, expected) {
@@ -854,9 +909,10 @@ func TestGetRegionChart(t *testing.T) {
 }
 
 func TestGetRateSummary(t *testing.T) {
+	now := time.Date(2008, time.September, 11, 13, 54, 50, 0, time.UTC)
 	server, zillow := testFixtures(t, rateSummaryPath, func(values url.Values) {
 		assertOnlyParam(t, values, stateParam, state)
-	})
+	}, WithClock(func() time.Time { return now }))
 	defer server.Close()
 
 	request := RateSummaryRequest{State: state}
@@ -866,6 +922,7 @@ func TestGetRateSummary(t *testing.T) {
 	}
 	expected := &RateSummary{
 		XMLName: xml.Name{Space: "http://www.zillow.com/static/xsd/RateSummary.xsd", Local: "rateSummary"},
+		base:    base{responsePresent: true},
 		Message: Message{
 			Text: "Request successfully processed",
 			Code: 0,
@@ -880,6 +937,7 @@ func TestGetRateSummary(t *testing.T) {
 			{LoanType: "fifteenYearFixed", Count: 5801, Value: 5.94},
 			{LoanType: "fiveOneARM", Count: 3148, Value: 5.71},
 		},
+		AsOf: "2008-09-11T13:54:50Z",
 	}
 
 	if !reflect.DeepEqual(result, expected) {
@@ -902,6 +960,7 @@ func TestGetMonthlyPayments(t *testing.T) {
 	}
 	expected := &MonthlyPayments{
 		XMLName: xml.Name{Space: "http://www.zillow.com/static/xsd/MonthlyPayments.xsd", Local: "paymentsSummary"},
+		base:    base{responsePresent: true},
 		Request: request,
 		Message: Message{
 			Text: "Request successfully processed",
@@ -968,6 +1027,7 @@ func TestCalculateMonthlyPaymentsAdvanced(t *testing.T) {
 	}
 	expected := &MonthlyPaymentsAdvanced{
 		XMLName: xml.Name{Space: "http://www.zillow.com/static/xsd/MonthlyPaymentsAdvanced.xsd", Local: "paymentsdetails"},
+		base:    base{responsePresent: true},
 		Request: request,
 		Message: Message{
 			Text: "Request successfully processed",
@@ -1020,7 +1080,7 @@ func TestCalculateAffordability(t *testing.T) {
 	down := 800000
 	rate := float32(6.504)
 	schedule := "yearly"
-	propertyTax := float32(20.0)
+	propertyTax := Percent(20.0)
 	hazard := 20000
 	pmi := 1000
 	hoa := 10000
@@ -1067,6 +1127,7 @@ func TestCalculateAffordability(t *testing.T) {
 	}
 	expected := &Affordability{
 		XMLName: xml.Name{Space: "static/xsd/CalculateAffordability.xsd", Local: "affordabilitydetails"},
+		base:    base{responsePresent: true},
 		Request: request,
 		Message: Message{
 			Text: "Request successfully processed",
@@ -1122,3 +1183,85 @@ func TestCalculateAffordability(t *testing.T) {
 		t.Fatalf("expected:\n %#v\n\n but got:\n %#v", prettyJSON(t, expected), prettyJSON(t, result))
 	}
 }
+
+// endpoints calls each endpoint with a valid request.
+var endpoints = []struct {
+	path string
+	call func(Zillow) (interface{}, error)
+}{
+	{zestimatePath, func(z Zillow) (interface{}, error) {
+		return z.GetZestimate(ZestimateRequest{Zpid: zpid})
+	}},
+	{searchResultsPath, func(z Zillow) (interface{}, error) {
+		return z.GetSearchResults(SearchRequest{Address: address, CityStateZip: citystatezip})
+	}},
+	{chartPath, func(z Zillow) (interface{}, error) {
+		return z.GetChart(ChartRequest{Zpid: zpid, UnitType: unitType, Width: width, Height: height})
+	}},
+	{compsPath, func(z Zillow) (interface{}, error) {
+		return z.GetComps(CompsRequest{Zpid: zpid, Count: count})
+	}},
+	{deepCompsPath, func(z Zillow) (interface{}, error) {
+		return z.GetDeepComps(CompsRequest{Zpid: zpid, Count: count})
+	}},
+	{deepSearchPath, func(z Zillow) (interface{}, error) {
+		return z.GetDeepSearchResults(SearchRequest{Address: address, CityStateZip: citystatezip})
+	}},
+	{updatedPropertyDetailsPath, func(z Zillow) (interface{}, error) {
+		return z.GetUpdatedPropertyDetails(UpdatedPropertyDetailsRequest{Zpid: zpid})
+	}},
+	{regionChildrenPath, func(z Zillow) (interface{}, error) {
+		return z.GetRegionChildren(RegionChildrenRequest{City: regionCity, State: regionState, ChildType: childType})
+	}},
+	{regionChartPath, func(z Zillow) (interface{}, error) {
+		return z.GetRegionChart(RegionChartRequest{City: city, State: state, UnitType: unitType, Width: width, Height: height})
+	}},
+	{rateSummaryPath, func(z Zillow) (interface{}, error) {
+		return z.GetRateSummary(RateSummaryRequest{})
+	}},
+	{monthlyPaymentsPath, func(z Zillow) (interface{}, error) {
+		return z.GetMonthlyPayments(MonthlyPaymentsRequest{Price: price, Down: down, Zip: zip})
+	}},
+	{monthlyPaymentsAdvancedPath, func(z Zillow) (interface{}, error) {
+		return z.CalculateMonthlyPaymentsAdvanced(MonthlyPaymentsAdvancedRequest{Price: price, Rate: rate, Schedule: schedule, TermInMonths: termInMonths, Zip: zip})
+	}},
+	{affordabilityPath, func(z Zillow) (interface{}, error) {
+		return z.CalculateAffordability(AffordabilityRequest{AnnualIncome: annualIncome, MonthlyDebts: monthlyDebts, Rate: rate, Schedule: schedule, TermInMonths: termInMonths, Zip: zip})
+	}},
+}
+
+func TestZwsIdNotEchoed(t *testing.T) {
+	for _, e := range endpoints {
+		t.Run(e.path, func(t *testing.T) {
+			fixture, err := ioutil.ReadFile("testdata/" + e.path + ".xml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Echo the key back as if Zillow included it in the request block.
+			body := strings.Replace(string(fixture), "<request>", "<request><zws-id>"+testZwsId+"</zws-id>", 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, body)
+			}))
+			defer server.Close()
+
+			result, err := e.call(&zillow{zwsId: testZwsId, url: server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := fmt.Sprintf("%#v", result); strings.Contains(s, testZwsId) {
+				t.Fatalf("result exposes zws-id: %s", s)
+			}
+		})
+	}
+}
+
+// decodeFixture decodes testdata/<name>.xml into v.
+func decodeFixture(t *testing.T, name string, v interface{}) {
+	b, err := ioutil.ReadFile("testdata/" + name + ".xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decodeXML(b, v); err != nil {
+		t.Fatal(err)
+	}
+}